@@ -0,0 +1,407 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/boxtsecond/gosmgp/pkg"
+)
+
+var (
+	ErrRequestTimeout = errors.New("smgp client: request timeout")
+	ErrClientClosed   = errors.New("smgp client: client is closed")
+)
+
+const (
+	DefaultRequestTimeout = 30 * time.Second
+	DefaultRequestQueue   = 64 // 未处理请求包通道的缓冲大小
+)
+
+// 网关发来、未交由 Handler 处理的请求包
+type IncomingRequest struct {
+	Header *pkg.Header
+	Packer pkg.Packer
+}
+
+// 处理网关发来的请求包(Deliver 等)，返回的应答包以相同的 SequenceID 发回，为 nil 时不应答；
+// 可用 pkg.NewResponse(pkg.RequestID(h.RequestID), req, h.SequenceID) 生成默认应答
+type RequestHandler func(h *pkg.Header, req pkg.Packer) pkg.Packer
+
+// 一个已发出、等待应答的请求
+type Future struct {
+	SequenceID uint32
+
+	done    chan struct{}
+	rsp     pkg.Packer
+	err     error
+	timer   *time.Timer
+	release func() // 释放占用的窗口位置
+}
+
+// 收到应答、超时或连接关闭时关闭
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// 等待应答，ctx 结束时返回 ctx.Err()，但不会撤销已发出的请求
+func (f *Future) Wait(ctx context.Context) (pkg.Packer, error) {
+	select {
+	case <-f.done:
+		return f.rsp, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type SubmitFuture struct {
+	*Future
+}
+
+// 等待 Submit 应答，应答状态非0时同时返回应答包与对应的错误
+func (f SubmitFuture) Wait(ctx context.Context) (*pkg.SmgpSubmitRespPkt, error) {
+	p, err := f.Future.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rsp, ok := p.(*pkg.SmgpSubmitRespPkt)
+	if !ok {
+		return nil, ErrRespNotMatch
+	}
+	if rsp.Status.Data() != 0 {
+		return rsp, rsp.Status.Error()
+	}
+	return rsp, nil
+}
+
+// 异步客户端：由独立的 goroutine 读取网关发来的包，按 SequenceID 匹配应答，
+// 可在多个 goroutine 中并发发送请求
+type AsyncClient struct {
+	keepAlive // 链路检测，见 StartKeepAlive，须在首位
+
+	cli *Client
+
+	// 请求超时时间
+	Timeout time.Duration
+	// 处理网关发来的请求包(Deliver 等)；为空时请求包由 Requests() 交给调用方，不会代为应答。
+	// ActiveTest 与 Exit 总是自动应答
+	Handler RequestHandler
+	// 读取或应答出错时的回调，为空时忽略
+	ErrorHandler func(error)
+	// 限制未收到应答的 Submit 数，为空时不限制，须在发送请求前设置
+	Window *Window
+
+	wmu      sync.Mutex
+	mu       sync.Mutex
+	pending  map[uint32]*Future
+	requests chan *IncomingRequest
+	closed   bool
+	err      error
+	stop     chan struct{} // shutdown 时关闭
+	done     chan struct{}
+	once     sync.Once
+}
+
+// cli 须已通过 Connect 登录，之后不应再直接调用 cli 的收发方法
+func NewAsyncClient(cli *Client, timeout time.Duration) *AsyncClient {
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	a := &AsyncClient{
+		cli:      cli,
+		Timeout:  timeout,
+		pending:  make(map[uint32]*Future),
+		requests: make(chan *IncomingRequest, DefaultRequestQueue),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go a.readLoop()
+	return a
+}
+
+// 异步发送请求，返回等待应答的 Future
+func (a *AsyncClient) Go(req pkg.Packer) *Future {
+	f, ok := a.register()
+	if ok {
+		a.sendPending(f, req)
+	}
+	return f
+}
+
+// 发送请求并等待应答
+func (a *AsyncClient) Request(ctx context.Context, req pkg.Packer) (pkg.Packer, error) {
+	return a.Go(req).Wait(ctx)
+}
+
+// 异步发送 Submit，设置了 TPS 时先阻塞等待限速器放行；
+// 窗口已满时请求排队等待，超时时间从进入队列时算起
+func (a *AsyncClient) SubmitAsync(p *pkg.SmgpSubmitReqPkt) SubmitFuture {
+	if l := a.cli.limiter; l != nil && !l.waitN(1, a.done, nil) {
+		f, _ := a.register()
+		return SubmitFuture{f}
+	}
+	return SubmitFuture{a.goWindowed(p, false)}
+}
+
+// 发送 Submit 并等待应答，先等待限速器放行，窗口已满时阻塞，直到有位置释放或 ctx 结束
+func (a *AsyncClient) Submit(ctx context.Context, p *pkg.SmgpSubmitReqPkt) (*pkg.SmgpSubmitRespPkt, error) {
+	if l := a.cli.limiter; l != nil && !l.waitN(1, ctx.Done(), a.done) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, a.Err()
+	}
+	if a.Window != nil {
+		if !a.Window.acquire(ctx.Done(), a.done) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return nil, a.Err()
+		}
+		return SubmitFuture{a.goWindowed(p, true)}.Wait(ctx)
+	}
+	// 已取得令牌，不能再经过 SubmitAsync
+	return SubmitFuture{a.Go(p)}.Wait(ctx)
+}
+
+// 登记一个等待应答的请求并开始计时，连接已关闭时返回已结束的 Future 与 false
+func (a *AsyncClient) register() (*Future, bool) {
+	seq := <-a.cli.conn.SequenceID
+	f := &Future{SequenceID: seq, done: make(chan struct{})}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		f.err = a.closeErr()
+		close(f.done)
+		return f, false
+	}
+	// 先登记再发送，避免应答先于登记到达
+	a.pending[seq] = f
+	f.timer = time.AfterFunc(a.Timeout, func() {
+		a.complete(seq, nil, ErrRequestTimeout)
+	})
+	return f, true
+}
+
+func (a *AsyncClient) sendPending(f *Future, req pkg.Packer) {
+	if err := a.send(req, f.SequenceID); err != nil {
+		a.complete(f.SequenceID, nil, err)
+	}
+}
+
+// 占用窗口位置后发送，acquired 为 true 表示调用方已占用；
+// 未取得位置的请求由单独的 goroutine 等待，请求超时或连接关闭时放弃
+func (a *AsyncClient) goWindowed(req pkg.Packer, acquired bool) *Future {
+	w := a.Window
+	if w == nil {
+		return a.Go(req)
+	}
+
+	f, ok := a.register()
+	if !ok {
+		if acquired {
+			w.Release()
+		}
+		return f
+	}
+
+	if acquired || w.TryAcquire() {
+		if a.attachSlot(f, w) {
+			a.sendPending(f, req)
+		}
+		return f
+	}
+
+	go func() {
+		if !w.acquire(f.done, a.done) {
+			return
+		}
+		if a.attachSlot(f, w) {
+			a.sendPending(f, req)
+		}
+	}()
+	return f
+}
+
+// 把占用的窗口位置交给 f，请求已结束时立即释放并返回 false
+func (a *AsyncClient) attachSlot(f *Future, w *Window) bool {
+	a.mu.Lock()
+	if a.pending[f.SequenceID] != f {
+		a.mu.Unlock()
+		w.Release()
+		return false
+	}
+	f.release = w.Release
+	a.mu.Unlock()
+	return true
+}
+
+// Handler 为空时，网关发来的请求包(上行短信、状态报告等)从此通道依次取出，
+// 调用方处理后通过 Respond 应答。通道已满时读取 goroutine 阻塞，应答也无法匹配，
+// 调用方须持续读取；连接关闭后通道关闭
+func (a *AsyncClient) Requests() <-chan *IncomingRequest {
+	return a.requests
+}
+
+// 应答网关发来的请求包
+func (a *AsyncClient) Respond(h *pkg.Header, rsp pkg.Packer) error {
+	return a.send(rsp, h.SequenceID)
+}
+
+// 等待应答的请求数
+func (a *AsyncClient) Pending() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.pending)
+}
+
+// 读取 goroutine 退出时关闭
+func (a *AsyncClient) Done() <-chan struct{} {
+	return a.done
+}
+
+// 连接断开的原因，连接正常时为 nil
+func (a *AsyncClient) Err() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+// 发送 Exit 请求后关闭连接，未完成的请求均以 ErrClientClosed 结束
+func (a *AsyncClient) Close() error {
+	a.send(&pkg.SmgpExitReqPkt{}, <-a.cli.conn.SequenceID)
+	a.shutdown(ErrClientClosed)
+	<-a.done
+	return nil
+}
+
+func (a *AsyncClient) send(p pkg.Packer, seq uint32) error {
+	a.wmu.Lock()
+	defer a.wmu.Unlock()
+	return a.cli.conn.SendPkt(p, seq)
+}
+
+func (a *AsyncClient) complete(seq uint32, rsp pkg.Packer, err error) {
+	a.mu.Lock()
+	f, ok := a.pending[seq]
+	if ok {
+		delete(a.pending, seq)
+	}
+	a.mu.Unlock()
+	if !ok {
+		return
+	}
+	f.finish(rsp, err)
+}
+
+func (f *Future) finish(rsp pkg.Packer, err error) {
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+	if f.release != nil {
+		f.release()
+	}
+	f.rsp, f.err = rsp, err
+	close(f.done)
+}
+
+func (a *AsyncClient) closeErr() error {
+	if a.err != nil {
+		return a.err
+	}
+	return ErrClientClosed
+}
+
+// 标记关闭并关闭底层连接，使读取 goroutine 退出；
+// pkg.Conn 的状态只在读取 goroutine 中修改
+func (a *AsyncClient) shutdown(err error) {
+	a.once.Do(func() {
+		a.mu.Lock()
+		a.closed = true
+		if a.err == nil {
+			a.err = err
+		}
+		a.mu.Unlock()
+
+		close(a.stop)
+		a.cli.conn.Conn.Close()
+	})
+}
+
+func (a *AsyncClient) readLoop() {
+	defer close(a.done)
+	defer close(a.requests)
+
+	// 包装前同步查询期间收到的请求包
+	for _, r := range a.cli.unhandled {
+		a.handleRequest(r.Header, r.Packer)
+	}
+	a.cli.unhandled = nil
+
+	for {
+		h, p, err := a.cli.conn.RecvPkt(0)
+		if err != nil {
+			// 未登记编解码的命令已整包读出，跳过即可
+			if err == pkg.ErrRequestIDInvalid || err == pkg.ErrRequestIDNotSupported {
+				a.reportError(err)
+				continue
+			}
+			a.shutdown(err)
+			break
+		}
+		a.touch()
+
+		id := pkg.RequestID(h.RequestID)
+		if id.IsResponse() {
+			a.complete(h.SequenceID, p, nil)
+			continue
+		}
+		a.handleRequest(h, p)
+
+		if id == pkg.SMGP_EXIT {
+			a.shutdown(ErrClientClosed)
+			break
+		}
+	}
+
+	a.wmu.Lock()
+	a.cli.conn.Close()
+	a.wmu.Unlock()
+	a.cli.releaseLimiter()
+
+	// 连接已断开，结束所有未完成的请求
+	a.mu.Lock()
+	err := a.closeErr()
+	pending := a.pending
+	a.pending = make(map[uint32]*Future)
+	a.mu.Unlock()
+
+	for _, f := range pending {
+		f.finish(nil, err)
+	}
+}
+
+func (a *AsyncClient) handleRequest(h *pkg.Header, p pkg.Packer) {
+	rsp, handled := respond(a.Handler, h, p)
+	if !handled {
+		select {
+		case a.requests <- &IncomingRequest{Header: h, Packer: p}:
+		case <-a.stop:
+		}
+		return
+	}
+	if rsp == nil {
+		return
+	}
+	if err := a.send(rsp, h.SequenceID); err != nil {
+		a.reportError(err)
+	}
+}
+
+func (a *AsyncClient) reportError(err error) {
+	if a.ErrorHandler != nil {
+		a.ErrorHandler(err)
+	}
+}
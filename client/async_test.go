@@ -0,0 +1,230 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/boxtsecond/gosmgp/pkg"
+)
+
+// 模拟网关：应答登录，之后的每个包交给 handle 处理
+func fakeGateway(t *testing.T, handle func(c *pkg.Conn, h *pkg.Header, p pkg.Packer)) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			rw, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				c := pkg.NewConnection(rw, pkg.VERSION)
+				c.SetState(pkg.CONNECTION_CONNECTED)
+				defer c.Close()
+				for {
+					h, p, err := c.RecvPkt(0)
+					if err != nil {
+						return
+					}
+					if _, ok := p.(*pkg.SmgpLoginReqPkt); ok {
+						c.SendPkt(&pkg.SmgpLoginRespPkt{}, h.SequenceID)
+						continue
+					}
+					handle(c, h, p)
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func dialAccount(t *testing.T, addr, clientID string, tps float64, burst int) *Client {
+	cli := NewClient(pkg.VERSION)
+	cli.TPS, cli.Burst = tps, burst
+	if err := cli.Connect(addr, clientID, "secret", 0, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	return cli
+}
+
+func newAsync(t *testing.T, addr string, timeout time.Duration) *AsyncClient {
+	a := NewAsyncClient(dialAccount(t, addr, "10000001", 0, 0), timeout)
+	t.Cleanup(func() { a.Close() })
+	return a
+}
+
+func testSubmit(text string) *pkg.SmgpSubmitReqPkt {
+	return &pkg.SmgpSubmitReqPkt{MsgLength: uint8(len(text)), MsgContent: []byte(text)}
+}
+
+// 以 SequenceID 作为 MsgID 的流水号应答，content 为 "drop" 时不应答
+func echoSubmit(c *pkg.Conn, h *pkg.Header, p pkg.Packer) {
+	r, ok := p.(*pkg.SmgpSubmitReqPkt)
+	if !ok || string(r.MsgContent) == "drop" {
+		return
+	}
+	id, _ := pkg.GenMsgID("10061", h.SequenceID%1000000)
+	c.SendPkt(&pkg.SmgpSubmitRespPkt{MsgID: id}, h.SequenceID)
+}
+
+func TestAsyncClientCorrelation(t *testing.T) {
+	// 两个一组倒序应答
+	var mu sync.Mutex
+	var held []uint32
+	addr := fakeGateway(t, func(c *pkg.Conn, h *pkg.Header, p pkg.Packer) {
+		if _, ok := p.(*pkg.SmgpSubmitReqPkt); !ok {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		held = append(held, h.SequenceID)
+		if len(held) < 2 {
+			return
+		}
+		for i := len(held) - 1; i >= 0; i-- {
+			id, _ := pkg.GenMsgID("10061", held[i]%1000000)
+			c.SendPkt(&pkg.SmgpSubmitRespPkt{MsgID: id}, held[i])
+		}
+		held = nil
+	})
+	a := newAsync(t, addr, time.Second)
+
+	fs := []SubmitFuture{a.SubmitAsync(testSubmit("a")), a.SubmitAsync(testSubmit("b"))}
+	for _, f := range fs {
+		rsp, err := f.Wait(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seq, _ := rsp.MsgID.Sequence(); seq != f.SequenceID%1000000 {
+			t.Errorf("response %d matched to request %d", seq, f.SequenceID)
+		}
+	}
+	if n := a.Pending(); n != 0 {
+		t.Errorf("pending = %d, want 0", n)
+	}
+}
+
+func TestAsyncClientTimeoutAndClose(t *testing.T) {
+	addr := fakeGateway(t, echoSubmit)
+	a := newAsync(t, addr, 50*time.Millisecond)
+	ctx := context.Background()
+
+	tests := []struct {
+		text string
+		err  error
+	}{
+		{"ok", nil},
+		{"drop", ErrRequestTimeout},
+		{"ok", nil},
+	}
+	for _, tt := range tests {
+		if _, err := a.Submit(ctx, testSubmit(tt.text)); err != tt.err {
+			t.Errorf("Submit(%q) error = %v, want %v", tt.text, err, tt.err)
+		}
+	}
+	if n := a.Pending(); n != 0 {
+		t.Errorf("pending after timeout = %d, want 0", n)
+	}
+
+	f := a.SubmitAsync(testSubmit("drop"))
+	a.Close()
+	if _, err := f.Wait(ctx); err != ErrClientClosed {
+		t.Errorf("pending request error = %v, want %v", err, ErrClientClosed)
+	}
+	if _, err := a.Submit(ctx, testSubmit("ok")); err != ErrClientClosed {
+		t.Errorf("Submit after Close error = %v, want %v", err, ErrClientClosed)
+	}
+}
+
+func TestAsyncClientDeliverWithoutHandler(t *testing.T) {
+	msgID, _ := pkg.ParseMsgID("01006101161700012345")
+	acks := make(chan *pkg.SmgpDeliverRespPkt, 1)
+	addr := fakeGateway(t, func(c *pkg.Conn, h *pkg.Header, p pkg.Packer) {
+		switch r := p.(type) {
+		case *pkg.SmgpSubmitReqPkt:
+			c.SendPkt(&pkg.SmgpDeliverReqPkt{MsgID: msgID, SrcTermID: "1", DestTermID: "2"}, <-c.SequenceID)
+			echoSubmit(c, h, p)
+		case *pkg.SmgpDeliverRespPkt:
+			acks <- r
+		}
+	})
+	a := newAsync(t, addr, time.Second)
+
+	if _, err := a.Submit(context.Background(), testSubmit("ok")); err != nil {
+		t.Fatal(err)
+	}
+
+	var req *IncomingRequest
+	select {
+	case req = <-a.Requests():
+	case <-time.After(time.Second):
+		t.Fatal("deliver not passed to Requests")
+	}
+	d, ok := req.Packer.(*pkg.SmgpDeliverReqPkt)
+	if !ok || d.MsgID != msgID {
+		t.Fatalf("Requests got %v, want the Deliver", req.Packer)
+	}
+
+	// 调用方应答前网关不应收到应答
+	select {
+	case ack := <-acks:
+		t.Fatalf("deliver answered implicitly: %v", ack)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := a.Respond(req.Header, &pkg.SmgpDeliverRespPkt{MsgID: d.MsgID}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ack := <-acks:
+		if ack.MsgID != msgID {
+			t.Errorf("deliver resp MsgID = %s, want %s", ack.MsgID, msgID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("deliver resp not received")
+	}
+
+	a.Close()
+	if _, ok := <-a.Requests(); ok {
+		t.Error("Requests not closed after Close")
+	}
+}
+
+func TestAsyncClientDeliverWithHandler(t *testing.T) {
+	msgID, _ := pkg.ParseMsgID("01006101161700012345")
+	acks := make(chan *pkg.SmgpDeliverRespPkt, 1)
+	addr := fakeGateway(t, func(c *pkg.Conn, h *pkg.Header, p pkg.Packer) {
+		switch r := p.(type) {
+		case *pkg.SmgpSubmitReqPkt:
+			c.SendPkt(&pkg.SmgpDeliverReqPkt{MsgID: msgID, SrcTermID: "1", DestTermID: "2"}, <-c.SequenceID)
+			echoSubmit(c, h, p)
+		case *pkg.SmgpDeliverRespPkt:
+			acks <- r
+		}
+	})
+	a := NewAsyncClient(dialAccount(t, addr, "10000001", 0, 0), time.Second)
+	defer a.Close()
+	a.Handler = func(h *pkg.Header, req pkg.Packer) pkg.Packer {
+		rsp, _ := pkg.NewResponse(pkg.RequestID(h.RequestID), req, h.SequenceID)
+		return rsp
+	}
+
+	if _, err := a.Submit(context.Background(), testSubmit("ok")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ack := <-acks:
+		if ack.MsgID != msgID {
+			t.Errorf("deliver resp MsgID = %s, want %s", ack.MsgID, msgID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("deliver not answered by handler")
+	}
+}
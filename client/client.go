@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"net"
 	"time"
@@ -8,11 +9,33 @@ import (
 	"github.com/boxtsecond/gosmgp/pkg"
 )
 
-var ErrRespNotMatch = errors.New("the response is not matched with the request")
+var (
+	ErrRespNotMatch    = errors.New("the response is not matched with the request")
+	ErrRoutePageRepeat = errors.New("the route page does not advance LastRouteID")
+)
 
 type Client struct {
+	keepAlive // 链路检测，见 StartKeepAlive，须在首位
+
 	conn *pkg.Conn
 	ver  uint8
+
+	// 每秒最多发送的 Submit 数，0 表示不限速，须在 Connect 前设置；
+	// 同一 ClientID 的所有 Client 共用一个限速器，须设置相同的 TPS 与 Burst，否则 Connect 返回 ErrLimiterConflict
+	TPS float64
+	// 允许的突发 Submit 数，0 表示取 TPS
+	Burst int
+	// 处理等待查询应答期间网关发来的请求包(Deliver 等)；为空时这些请求不应答，
+	// 由之后的 RecvAndUnpackPkt 或 AsyncClient.Requests 依次返回，调用方自行应答。ActiveTest 与 Exit 总是自动应答
+	Handler RequestHandler
+
+	limiter   *RateLimiter
+	limiterID string             // 取得 limiter 的 ClientID，Disconnect 时释放
+	unhandled []*IncomingRequest // 等待查询应答期间收到、未交由 Handler 处理的请求包
+
+	kaStop   chan struct{}
+	kaDone   chan struct{}
+	linkDead int32
 }
 
 func NewClient(version uint8) *Client {
@@ -68,16 +91,51 @@ func (cli *Client) Connect(serverAddr, clientID, secret string, loginMode uint8,
 	}
 
 	cli.conn.SetState(pkg.CONNECTION_AUTHOK)
+	if cli.TPS > 0 {
+		if cli.limiter, err = AccountLimiter(clientID, cli.TPS, cli.Burst); err != nil {
+			return err
+		}
+		cli.limiterID = clientID
+	}
 	return nil
 }
 
+// 当前账号的限速器，未设置 TPS 时为 nil
+func (cli *Client) Limiter() *RateLimiter {
+	return cli.limiter
+}
+
+// 发送 n 条 Submit 前调用，等待限速器放行；长短信按分段数计
+func (cli *Client) WaitN(ctx context.Context, n int) error {
+	if cli.limiter == nil {
+		return nil
+	}
+	return cli.limiter.WaitN(ctx, n)
+}
+
 func (cli *Client) Disconnect() {
+	cli.stopKeepAlive()
 	if cli.conn != nil {
 		cli.conn.Close()
 	}
+	cli.releaseLimiter()
+}
+
+// 释放 Connect 取得的账号限速器，可重复调用
+func (cli *Client) releaseLimiter() {
+	if cli.limiterID != "" {
+		ReleaseAccountLimiter(cli.limiterID)
+		cli.limiterID = ""
+	}
 }
 
+// 发送 Submit 时按 TPS 限速，每个分段取一个令牌
 func (cli *Client) SendReqPkt(packet pkg.Packer) (uint32, error) {
+	if _, ok := packet.(*pkg.SmgpSubmitReqPkt); ok && cli.limiter != nil {
+		if err := cli.limiter.Wait(context.Background()); err != nil {
+			return 0, err
+		}
+	}
 	seq := <-cli.conn.SequenceID
 	return seq, cli.conn.SendPkt(packet, seq)
 }
@@ -86,6 +144,405 @@ func (cli *Client) SendRspPkt(packet pkg.Packer, sequenceID uint32) error {
 	return cli.conn.SendPkt(packet, sequenceID)
 }
 
+// 先依次返回等待查询应答期间收到、未处理的请求包。
+// ActiveTest 自动应答，ActiveTest 应答计入链路检测，均不返回给调用方
 func (cli *Client) RecvAndUnpackPkt(timeout time.Duration) (interface{}, error) {
-	return cli.conn.RecvAndUnpackPkt(timeout)
+	if len(cli.unhandled) > 0 {
+		r := cli.unhandled[0]
+		cli.unhandled = cli.unhandled[1:]
+		return r.Packer, nil
+	}
+
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	for {
+		var wait time.Duration
+		if !deadline.IsZero() {
+			if wait = time.Until(deadline); wait <= 0 {
+				return nil, ErrRequestTimeout
+			}
+		}
+
+		h, p, err := cli.conn.RecvPkt(wait)
+		if err != nil {
+			return nil, cli.linkErr(err)
+		}
+		cli.touch()
+
+		switch id := pkg.RequestID(h.RequestID); id {
+		case pkg.SMGP_ACTIVE_TEST:
+			rsp, _ := pkg.NewResponse(id, p, h.SequenceID)
+			if err := cli.SendRspPkt(rsp, h.SequenceID); err != nil {
+				return nil, cli.linkErr(err)
+			}
+			continue
+		case pkg.SMGP_ACTIVE_TEST_RESP:
+			continue
+		}
+		return p, nil
+	}
+}
+
+// 发送请求并读取 SequenceID 相同的应答包，返回应答包及请求的 SequenceID。
+// 期间收到的请求包交由 Handler 处理并应答，Handler 为空时留给 RecvAndUnpackPkt，
+// 其它应答包丢弃；
+// timeout 为等待应答的总时长，0 表示不超时
+func (cli *Client) sendAndRecv(req pkg.Packer, timeout time.Duration) (pkg.Packer, uint32, error) {
+	seq, err := cli.SendReqPkt(req)
+	if err != nil {
+		return nil, seq, err
+	}
+
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	for {
+		var wait time.Duration
+		if !deadline.IsZero() {
+			if wait = time.Until(deadline); wait <= 0 {
+				return nil, seq, ErrRequestTimeout
+			}
+		}
+
+		h, p, err := cli.conn.RecvPkt(wait)
+		if err != nil {
+			// 未登记编解码的命令已整包读出，跳过即可
+			if err == pkg.ErrRequestIDInvalid || err == pkg.ErrRequestIDNotSupported {
+				continue
+			}
+			return nil, seq, cli.linkErr(err)
+		}
+		cli.touch()
+
+		id := pkg.RequestID(h.RequestID)
+		if id.IsResponse() {
+			if h.SequenceID == seq {
+				return p, seq, nil
+			}
+			continue
+		}
+
+		rsp, handled := respond(cli.Handler, h, p)
+		if !handled {
+			cli.unhandled = append(cli.unhandled, &IncomingRequest{Header: h, Packer: p})
+			continue
+		}
+		if rsp != nil {
+			if err := cli.SendRspPkt(rsp, h.SequenceID); err != nil {
+				return nil, seq, err
+			}
+		}
+		if id == pkg.SMGP_EXIT {
+			cli.conn.Close()
+			return nil, seq, ErrClientClosed
+		}
+	}
+}
+
+// 生成请求包的应答。ActiveTest 与 Exit 总是自动应答，其它请求交由 handler 处理；
+// handler 为空时返回 false，请求须交给调用方，不能代为应答，以免丢失上行短信或状态报告
+func respond(handler RequestHandler, h *pkg.Header, p pkg.Packer) (pkg.Packer, bool) {
+	id := pkg.RequestID(h.RequestID)
+	if id == pkg.SMGP_EXIT || id == pkg.SMGP_ACTIVE_TEST {
+		rsp, _ := pkg.NewResponse(id, p, h.SequenceID)
+		return rsp, true
+	}
+	if handler == nil {
+		return nil, false
+	}
+	return handler(h, p), true
+}
+
+// 查询终端号码所属网关的路由
+func (cli *Client) QueryTERoute(srcGatewayID, termID string, timeout time.Duration) (*pkg.TERoute, error) {
+	req := &pkg.SmgpQueryTERouteReqPkt{
+		SrcGatewayID: srcGatewayID,
+		QueryTermID:  termID,
+	}
+
+	p, seq, err := cli.sendAndRecv(req, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, ok := p.(*pkg.SmgpQueryTERouteRespPkt)
+	if !ok || rsp.SequenceID != seq {
+		return nil, ErrRespNotMatch
+	}
+
+	if rsp.Status.Data() != 0 {
+		return nil, rsp.Status.Error()
+	}
+	return &rsp.Route, nil
+}
+
+// 查询SP服务代码所属网关的路由
+func (cli *Client) QuerySPRoute(srcGatewayID, spCode string, timeout time.Duration) (*pkg.SPRoute, error) {
+	req := &pkg.SmgpQuerySPRouteReqPkt{
+		SrcGatewayID: srcGatewayID,
+		SPCode:       spCode,
+	}
+
+	p, seq, err := cli.sendAndRecv(req, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, ok := p.(*pkg.SmgpQuerySPRouteRespPkt)
+	if !ok || rsp.SequenceID != seq {
+		return nil, ErrRespNotMatch
+	}
+
+	if rsp.Status.Data() != 0 {
+		return nil, rsp.Status.Error()
+	}
+	return &rsp.Route, nil
+}
+
+// 请求网关更新终端路由，返回网关分配或确认的路由编号
+func (cli *Client) UpdateTERoute(updateType uint8, route pkg.TERoute, timeout time.Duration) (uint32, error) {
+	req := &pkg.SmgpUpdateTERouteReqPkt{
+		UpdateType: updateType,
+		Route:      route,
+	}
+
+	p, seq, err := cli.sendAndRecv(req, timeout)
+	if err != nil {
+		return 0, err
+	}
+
+	rsp, ok := p.(*pkg.SmgpUpdateTERouteRespPkt)
+	if !ok || rsp.SequenceID != seq {
+		return 0, ErrRespNotMatch
+	}
+
+	if rsp.Status.Data() != 0 {
+		return 0, rsp.Status.Error()
+	}
+	return rsp.RouteID, nil
+}
+
+// 请求网关更新SP路由，返回网关分配或确认的路由编号
+func (cli *Client) UpdateSPRoute(updateType uint8, route pkg.SPRoute, timeout time.Duration) (uint32, error) {
+	req := &pkg.SmgpUpdateSPRouteReqPkt{
+		UpdateType: updateType,
+		Route:      route,
+	}
+
+	p, seq, err := cli.sendAndRecv(req, timeout)
+	if err != nil {
+		return 0, err
+	}
+
+	rsp, ok := p.(*pkg.SmgpUpdateSPRouteRespPkt)
+	if !ok || rsp.SequenceID != seq {
+		return 0, ErrRespNotMatch
+	}
+
+	if rsp.Status.Data() != 0 {
+		return 0, rsp.Status.Error()
+	}
+	return rsp.RouteID, nil
+}
+
+// 遍历网关全部终端路由，按页发送 SMGP_GET_ALL_TE_ROUTE 请求，
+// 循环调用 Next 直至返回 false，再通过 Err 检查是否出错
+func (cli *Client) TERoutes(srcGatewayID string, timeout time.Duration) *TERouteIterator {
+	return &TERouteIterator{
+		cli:          cli,
+		srcGatewayID: srcGatewayID,
+		timeout:      timeout,
+	}
+}
+
+type TERouteIterator struct {
+	cli          *Client
+	srcGatewayID string
+	timeout      time.Duration
+
+	lastRouteID uint32
+	fetched     bool
+	page        []pkg.TERoute
+	cur         *pkg.TERoute
+	done        bool
+	err         error
+}
+
+func (it *TERouteIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if len(it.page) == 0 {
+		if it.done {
+			return false
+		}
+		it.fetch()
+		if it.err != nil || len(it.page) == 0 {
+			return false
+		}
+	}
+
+	it.cur = &it.page[0]
+	it.page = it.page[1:]
+	return true
+}
+
+func (it *TERouteIterator) fetch() {
+	req := &pkg.SmgpGetAllTERouteReqPkt{
+		SrcGatewayID: it.srcGatewayID,
+		LastRouteID:  it.lastRouteID,
+	}
+
+	p, seq, err := it.cli.sendAndRecv(req, it.timeout)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	rsp, ok := p.(*pkg.SmgpGetAllTERouteRespPkt)
+	if !ok || rsp.SequenceID != seq {
+		it.err = ErrRespNotMatch
+		return
+	}
+
+	if rsp.Status.Data() != 0 {
+		it.err = rsp.Status.Error()
+		return
+	}
+
+	if len(rsp.Routes) == 0 {
+		it.done = true
+		return
+	}
+	// 网关重复返回同一页或路由编号不递增时停止，避免无限循环
+	last := rsp.Routes[len(rsp.Routes)-1].RouteID
+	if it.fetched && last <= it.lastRouteID {
+		it.err = ErrRoutePageRepeat
+		return
+	}
+	it.page = rsp.Routes
+	it.lastRouteID = last
+	it.fetched = true
+}
+
+func (it *TERouteIterator) Route() *pkg.TERoute {
+	return it.cur
+}
+
+func (it *TERouteIterator) Err() error {
+	return it.err
+}
+
+// 遍历网关全部SP路由，按页发送 SMGP_GET_ALL_SP_ROUTE 请求，用法同 TERoutes
+func (cli *Client) SPRoutes(srcGatewayID string, timeout time.Duration) *SPRouteIterator {
+	return &SPRouteIterator{
+		cli:          cli,
+		srcGatewayID: srcGatewayID,
+		timeout:      timeout,
+	}
+}
+
+type SPRouteIterator struct {
+	cli          *Client
+	srcGatewayID string
+	timeout      time.Duration
+
+	lastRouteID uint32
+	fetched     bool
+	page        []pkg.SPRoute
+	cur         *pkg.SPRoute
+	done        bool
+	err         error
+}
+
+func (it *SPRouteIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if len(it.page) == 0 {
+		if it.done {
+			return false
+		}
+		it.fetch()
+		if it.err != nil || len(it.page) == 0 {
+			return false
+		}
+	}
+
+	it.cur = &it.page[0]
+	it.page = it.page[1:]
+	return true
+}
+
+func (it *SPRouteIterator) fetch() {
+	req := &pkg.SmgpGetAllSPRouteReqPkt{
+		SrcGatewayID: it.srcGatewayID,
+		LastRouteID:  it.lastRouteID,
+	}
+
+	p, seq, err := it.cli.sendAndRecv(req, it.timeout)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	rsp, ok := p.(*pkg.SmgpGetAllSPRouteRespPkt)
+	if !ok || rsp.SequenceID != seq {
+		it.err = ErrRespNotMatch
+		return
+	}
+
+	if rsp.Status.Data() != 0 {
+		it.err = rsp.Status.Error()
+		return
+	}
+
+	if len(rsp.Routes) == 0 {
+		it.done = true
+		return
+	}
+	// 网关重复返回同一页或路由编号不递增时停止，避免无限循环
+	last := rsp.Routes[len(rsp.Routes)-1].RouteID
+	if it.fetched && last <= it.lastRouteID {
+		it.err = ErrRoutePageRepeat
+		return
+	}
+	it.page = rsp.Routes
+	it.lastRouteID = last
+	it.fetched = true
+}
+
+func (it *SPRouteIterator) Route() *pkg.SPRoute {
+	return it.cur
+}
+
+func (it *SPRouteIterator) Err() error {
+	return it.err
+}
+
+// 查询用户状态，可在下发前跳过停机或不存在的号码
+func (cli *Client) QueryUserState(number string, timeout time.Duration) (pkg.UserState, error) {
+	req := &pkg.SmgpQueryUserStateReqPkt{
+		QueryUserAddr: number,
+	}
+
+	p, seq, err := cli.sendAndRecv(req, timeout)
+	if err != nil {
+		return 0, err
+	}
+
+	rsp, ok := p.(*pkg.SmgpQueryUserStateRespPkt)
+	if !ok || rsp.SequenceID != seq {
+		return 0, ErrRespNotMatch
+	}
+
+	if rsp.Status.Data() != 0 {
+		return 0, rsp.Status.Error()
+	}
+	return rsp.UserStatus, nil
 }
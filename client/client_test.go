@@ -0,0 +1,122 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/boxtsecond/gosmgp/pkg"
+)
+
+// 网关在应答查询前先下发 Deliver、ActiveTest 及一个无关的应答
+func interleavingGateway(t *testing.T, msgID pkg.MsgID, answered chan<- pkg.RequestID) string {
+	return fakeGateway(t, func(c *pkg.Conn, h *pkg.Header, p pkg.Packer) {
+		switch p.(type) {
+		case *pkg.SmgpQueryUserStateReqPkt:
+			c.SendPkt(&pkg.SmgpDeliverReqPkt{MsgID: msgID, SrcTermID: "1", DestTermID: "2"}, <-c.SequenceID)
+			c.SendPkt(&pkg.SmgpActiveTestReqPkt{}, <-c.SequenceID)
+			c.SendPkt(&pkg.SmgpSubmitRespPkt{}, h.SequenceID+1000)
+			c.SendPkt(&pkg.SmgpQueryUserStateRespPkt{UserStatus: pkg.USER_SUSPENDED}, h.SequenceID)
+		case *pkg.SmgpDeliverRespPkt, *pkg.SmgpActiveTestRespPkt:
+			answered <- pkg.RequestID(h.RequestID)
+		}
+	})
+}
+
+// 收集 wait 时长内网关收到的应答
+func collectAnswers(answered <-chan pkg.RequestID, wait time.Duration) map[pkg.RequestID]bool {
+	got := map[pkg.RequestID]bool{}
+	timer := time.After(wait)
+	for {
+		select {
+		case id := <-answered:
+			got[id] = true
+		case <-timer:
+			return got
+		}
+	}
+}
+
+func TestQueryWithHandler(t *testing.T) {
+	msgID, _ := pkg.ParseMsgID("01006101161700012345")
+	answered := make(chan pkg.RequestID, 4)
+	addr := interleavingGateway(t, msgID, answered)
+
+	cli := dialAccount(t, addr, "10000001", 0, 0)
+	defer cli.Disconnect()
+	var handled []pkg.Packer
+	cli.Handler = func(h *pkg.Header, req pkg.Packer) pkg.Packer {
+		handled = append(handled, req)
+		rsp, _ := pkg.NewResponse(pkg.RequestID(h.RequestID), req, h.SequenceID)
+		return rsp
+	}
+
+	state, err := cli.QueryUserState("8618012345678", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != pkg.USER_SUSPENDED {
+		t.Errorf("state = %v, want %v", state, pkg.USER_SUSPENDED)
+	}
+	if len(handled) != 1 {
+		t.Fatalf("handler called %d times, want 1 (Deliver only)", len(handled))
+	}
+	if _, ok := handled[0].(*pkg.SmgpDeliverReqPkt); !ok {
+		t.Errorf("handler got %T, want Deliver", handled[0])
+	}
+	got := collectAnswers(answered, 200*time.Millisecond)
+	if !got[pkg.SMGP_DELIVER_RESP] || !got[pkg.SMGP_ACTIVE_TEST_RESP] {
+		t.Errorf("requests answered: %v", got)
+	}
+}
+
+func TestQueryWithoutHandlerKeepsRequests(t *testing.T) {
+	msgID, _ := pkg.ParseMsgID("01006101161700012345")
+	answered := make(chan pkg.RequestID, 4)
+	addr := interleavingGateway(t, msgID, answered)
+
+	cli := dialAccount(t, addr, "10000001", 0, 0)
+	defer cli.Disconnect()
+
+	if _, err := cli.QueryUserState("8618012345678", time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// 只有 ActiveTest 自动应答，Deliver 不能代为应答
+	got := collectAnswers(answered, 200*time.Millisecond)
+	if !got[pkg.SMGP_ACTIVE_TEST_RESP] || got[pkg.SMGP_DELIVER_RESP] {
+		t.Errorf("requests answered: %v, want ActiveTest only", got)
+	}
+
+	p, err := cli.RecvAndUnpackPkt(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, ok := p.(*pkg.SmgpDeliverReqPkt)
+	if !ok || d.MsgID != msgID {
+		t.Fatalf("RecvAndUnpackPkt = %v, want the queued Deliver", p)
+	}
+	if err := cli.SendRspPkt(&pkg.SmgpDeliverRespPkt{MsgID: d.MsgID}, d.SequenceID); err != nil {
+		t.Fatal(err)
+	}
+	if got := collectAnswers(answered, 200*time.Millisecond); !got[pkg.SMGP_DELIVER_RESP] {
+		t.Errorf("Deliver answered by caller not received: %v", got)
+	}
+}
+
+func TestQueryTimeout(t *testing.T) {
+	addr := fakeGateway(t, func(c *pkg.Conn, h *pkg.Header, p pkg.Packer) {
+		if _, ok := p.(*pkg.SmgpQueryUserStateReqPkt); ok {
+			c.SendPkt(&pkg.SmgpActiveTestReqPkt{}, <-c.SequenceID)
+		}
+	})
+	cli := dialAccount(t, addr, "10000001", 0, 0)
+	defer cli.Disconnect()
+
+	start := time.Now()
+	if _, err := cli.QueryUserState("8618012345678", 100*time.Millisecond); err == nil {
+		t.Fatal("query without response returned no error")
+	}
+	if el := time.Since(start); el > 500*time.Millisecond {
+		t.Errorf("query timed out after %v, want about 100ms", el)
+	}
+}
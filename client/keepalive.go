@@ -0,0 +1,119 @@
+package client
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/boxtsecond/gosmgp/pkg"
+)
+
+var ErrLinkDead = errors.New("smgp client: no active test response returned, link is dead")
+
+const (
+	DefaultActiveTestInterval  = 60 * time.Second // 链路空闲多久后发送 ActiveTest
+	DefaultActiveTestMaxMissed = 3                // 连续多少次 ActiveTest 无应答时断开
+)
+
+// 链路检测状态，内嵌于 Client 与 AsyncClient 的首位以保证 lastRecv 64 位对齐
+type keepAlive struct {
+	lastRecv int64
+	missed   int32
+}
+
+// 未应答的 ActiveTest 数
+func (k *keepAlive) Missed() int {
+	return int(atomic.LoadInt32(&k.missed))
+}
+
+// 收到包时调用，重置链路检测计数
+func (k *keepAlive) touch() {
+	atomic.StoreInt64(&k.lastRecv, time.Now().UnixNano())
+	atomic.StoreInt32(&k.missed, 0)
+}
+
+// 空闲 interval 后调用 probe 发送 ActiveTest，连续 n 次无应答时以 ErrLinkDead 调用 dead，
+// 发送失败时立即以发送的错误调用 dead，之后退出；stop 关闭时退出
+func (k *keepAlive) run(interval time.Duration, n int, stop <-chan struct{}, probe func() error, dead func(error)) {
+	if interval <= 0 {
+		interval = DefaultActiveTestInterval
+	}
+	if n <= 0 {
+		n = DefaultActiveTestMaxMissed
+	}
+	k.touch()
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+			if time.Since(time.Unix(0, atomic.LoadInt64(&k.lastRecv))) < interval {
+				continue
+			}
+			if int(atomic.LoadInt32(&k.missed)) >= n {
+				dead(ErrLinkDead)
+				return
+			}
+			if err := probe(); err != nil {
+				dead(err)
+				return
+			}
+			atomic.AddInt32(&k.missed, 1)
+		}
+	}
+}
+
+// 开始链路检测：interval 内没有收到网关的任何包时发送 ActiveTest，
+// 连续 n 次无应答或发送失败则认为链路已断开，关闭连接，Err() 返回 ErrLinkDead。
+// 收到任何包都会重新计数，只需调用一次，连接关闭时自动停止
+func (a *AsyncClient) StartKeepAlive(interval time.Duration, n int) {
+	go a.keepAlive.run(interval, n, a.done, func() error {
+		// 应答由 readLoop 收到后重置计数，不需要等待
+		return a.send(&pkg.SmgpActiveTestReqPkt{}, <-a.cli.conn.SequenceID)
+	}, func(cause error) {
+		a.reportError(cause)
+		a.shutdown(ErrLinkDead)
+	})
+}
+
+// 开始链路检测，规则同 AsyncClient.StartKeepAlive。
+// ActiveTest 应答只在 RecvAndUnpackPkt 或查询方法读取时计入，调用方须持续读取；
+// 链路断开后关闭底层连接，RecvAndUnpackPkt 与查询方法返回 ErrLinkDead。
+// Disconnect 时停止；包装为 AsyncClient 后应改用 AsyncClient.StartKeepAlive
+func (cli *Client) StartKeepAlive(interval time.Duration, n int) {
+	if cli.kaStop != nil {
+		return
+	}
+	cli.kaStop, cli.kaDone = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(cli.kaDone)
+		cli.keepAlive.run(interval, n, cli.kaStop, func() error {
+			return cli.conn.SendPkt(&pkg.SmgpActiveTestReqPkt{}, <-cli.conn.SequenceID)
+		}, func(error) {
+			atomic.StoreInt32(&cli.linkDead, 1)
+			// 只关闭底层连接，pkg.Conn 的状态留给 Disconnect 修改
+			cli.conn.Conn.Close()
+		})
+	}()
+}
+
+// 停止链路检测并等待其退出
+func (cli *Client) stopKeepAlive() {
+	if cli.kaStop == nil {
+		return
+	}
+	close(cli.kaStop)
+	<-cli.kaDone
+	cli.kaStop = nil
+}
+
+// 链路已被判定断开时返回 ErrLinkDead，否则原样返回 err
+func (cli *Client) linkErr(err error) error {
+	if atomic.LoadInt32(&cli.linkDead) != 0 {
+		return ErrLinkDead
+	}
+	return err
+}
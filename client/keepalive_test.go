@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/boxtsecond/gosmgp/pkg"
+)
+
+func TestKeepAliveDeclaresLinkDead(t *testing.T) {
+	tests := []struct {
+		maxMissed int
+	}{
+		{1},
+		{3},
+	}
+	for _, tt := range tests {
+		var probes int32
+		addr := fakeGateway(t, func(c *pkg.Conn, h *pkg.Header, p pkg.Packer) {
+			if _, ok := p.(*pkg.SmgpActiveTestReqPkt); ok {
+				atomic.AddInt32(&probes, 1)
+			}
+		})
+		a := newAsync(t, addr, time.Second)
+		a.StartKeepAlive(20*time.Millisecond, tt.maxMissed)
+
+		select {
+		case <-a.Done():
+		case <-time.After(2 * time.Second):
+			t.Fatalf("maxMissed %d: link not declared dead", tt.maxMissed)
+		}
+		if err := a.Err(); err != ErrLinkDead {
+			t.Errorf("maxMissed %d: Err() = %v, want %v", tt.maxMissed, err, ErrLinkDead)
+		}
+		if n := atomic.LoadInt32(&probes); int(n) != tt.maxMissed {
+			t.Errorf("maxMissed %d: sent %d probes", tt.maxMissed, n)
+		}
+	}
+}
+
+func TestKeepAliveAnsweredLinkStaysUp(t *testing.T) {
+	var probes int32
+	addr := fakeGateway(t, func(c *pkg.Conn, h *pkg.Header, p pkg.Packer) {
+		if _, ok := p.(*pkg.SmgpActiveTestReqPkt); ok {
+			atomic.AddInt32(&probes, 1)
+			c.SendPkt(&pkg.SmgpActiveTestRespPkt{}, h.SequenceID)
+		}
+	})
+	a := newAsync(t, addr, time.Second)
+	a.StartKeepAlive(20*time.Millisecond, 2)
+
+	time.Sleep(300 * time.Millisecond)
+	if err := a.Err(); err != nil {
+		t.Fatalf("answered link closed: %v", err)
+	}
+	if atomic.LoadInt32(&probes) == 0 {
+		t.Error("no probe sent on an idle link")
+	}
+}
+
+func TestAsyncClientAnswersActiveTest(t *testing.T) {
+	answered := make(chan uint32, 1)
+	addr := fakeGateway(t, func(c *pkg.Conn, h *pkg.Header, p pkg.Packer) {
+		switch p.(type) {
+		case *pkg.SmgpSubmitReqPkt:
+			c.SendPkt(&pkg.SmgpActiveTestReqPkt{}, 7)
+			echoSubmit(c, h, p)
+		case *pkg.SmgpActiveTestRespPkt:
+			answered <- h.SequenceID
+		}
+	})
+	a := newAsync(t, addr, time.Second)
+	// 自定义 Handler 不应接管 ActiveTest
+	a.Handler = func(h *pkg.Header, req pkg.Packer) pkg.Packer { return nil }
+
+	if _, err := a.Submit(context.Background(), testSubmit("ok")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case seq := <-answered:
+		if seq != 7 {
+			t.Errorf("active test resp SequenceID = %d, want 7", seq)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("active test not answered")
+	}
+}
+
+func TestAsyncClientKeepAliveSendFailure(t *testing.T) {
+	var probes int32
+	addr := fakeGateway(t, func(c *pkg.Conn, h *pkg.Header, p pkg.Packer) {
+		if _, ok := p.(*pkg.SmgpActiveTestReqPkt); ok {
+			atomic.AddInt32(&probes, 1)
+		}
+	})
+	a := newAsync(t, addr, time.Second)
+	var reported error
+	a.ErrorHandler = func(err error) { reported = err }
+
+	// 写超时后发送 ActiveTest 失败，应立即判定链路断开，不计为未应答
+	a.cli.conn.Conn.SetWriteDeadline(time.Unix(1, 0))
+	a.StartKeepAlive(20*time.Millisecond, 5)
+
+	select {
+	case <-a.Done():
+	case <-time.After(time.Second):
+		t.Fatal("link not declared dead after a failed probe")
+	}
+	if err := a.Err(); err != ErrLinkDead {
+		t.Errorf("Err() = %v, want %v", err, ErrLinkDead)
+	}
+	if reported == nil || reported == ErrLinkDead {
+		t.Errorf("send error not reported: %v", reported)
+	}
+	if n := atomic.LoadInt32(&probes); n != 0 {
+		t.Errorf("gateway received %d probes", n)
+	}
+}
+
+func TestClientKeepAlive(t *testing.T) {
+	tests := []struct {
+		name   string
+		answer bool
+	}{
+		{"unanswered", false},
+		{"answered", true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var probes int32
+			addr := fakeGateway(t, func(c *pkg.Conn, h *pkg.Header, p pkg.Packer) {
+				if _, ok := p.(*pkg.SmgpActiveTestReqPkt); ok {
+					atomic.AddInt32(&probes, 1)
+					if tt.answer {
+						c.SendPkt(&pkg.SmgpActiveTestRespPkt{}, h.SequenceID)
+					}
+				}
+			})
+			cli := dialAccount(t, addr, "10000001", 0, 0)
+			defer cli.Disconnect()
+			cli.StartKeepAlive(20*time.Millisecond, 2)
+
+			// 应答的 ActiveTest 不返回给调用方，读满 300ms 超时；无应答时链路断开
+			p, err := cli.RecvAndUnpackPkt(300 * time.Millisecond)
+			if tt.answer {
+				if ne, ok := err.(net.Error); (!ok || !ne.Timeout()) && err != ErrRequestTimeout {
+					t.Fatalf("RecvAndUnpackPkt = %v, %v, want a timeout", p, err)
+				}
+			} else if err != ErrLinkDead {
+				t.Fatalf("RecvAndUnpackPkt = %v, %v, want %v", p, err, ErrLinkDead)
+			}
+			if n := atomic.LoadInt32(&probes); n < 2 {
+				t.Errorf("sent %d probes, want >= 2", n)
+			}
+		})
+	}
+}
+
+func TestClientAnswersActiveTest(t *testing.T) {
+	answered := make(chan uint32, 1)
+	addr := fakeGateway(t, func(c *pkg.Conn, h *pkg.Header, p pkg.Packer) {
+		switch p.(type) {
+		case *pkg.SmgpSubmitReqPkt:
+			c.SendPkt(&pkg.SmgpActiveTestReqPkt{}, 9)
+			echoSubmit(c, h, p)
+		case *pkg.SmgpActiveTestRespPkt:
+			answered <- h.SequenceID
+		}
+	})
+	cli := dialAccount(t, addr, "10000001", 0, 0)
+	defer cli.Disconnect()
+
+	if _, err := cli.SendReqPkt(testSubmit("ok")); err != nil {
+		t.Fatal(err)
+	}
+	p, err := cli.RecvAndUnpackPkt(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.(*pkg.SmgpSubmitRespPkt); !ok {
+		t.Fatalf("RecvAndUnpackPkt = %T, want the submit response", p)
+	}
+	select {
+	case seq := <-answered:
+		if seq != 9 {
+			t.Errorf("active test resp SequenceID = %d, want 9", seq)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("active test not answered")
+	}
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrLimiterConflict = errors.New("smgp client: the account limiter already exists with a different rate")
+
+// 令牌桶限速器，可并发使用
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // 每秒产生的令牌数
+	burst  int     // 桶容量
+	tokens float64
+	last   time.Time
+}
+
+// tps 为每秒允许的请求数，burst 为允许的突发请求数，不大于0时取 tps 向上取整
+func NewRateLimiter(tps float64, burst int) *RateLimiter {
+	l := &RateLimiter{last: time.Now()}
+	l.SetRate(tps, burst)
+	l.tokens = float64(l.burst)
+	return l
+}
+
+// 修改速率与突发数，tps 不大于0时不限速
+func (l *RateLimiter) SetRate(tps float64, burst int) {
+	burst = defaultBurst(tps, burst)
+
+	l.mu.Lock()
+	l.advance(time.Now())
+	l.rate, l.burst = tps, burst
+	if l.tokens > float64(burst) {
+		l.tokens = float64(burst)
+	}
+	l.mu.Unlock()
+}
+
+// 当前速率与突发数
+func (l *RateLimiter) Rate() (float64, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate, l.burst
+}
+
+// 取一个令牌，没有可用令牌时阻塞，直到取得或 ctx 结束
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	return l.WaitN(ctx, 1)
+}
+
+// 取 n 个令牌，长短信按分段数取；n 可以大于突发数，此时需等待更长时间
+func (l *RateLimiter) WaitN(ctx context.Context, n int) error {
+	if !l.waitN(n, ctx.Done(), nil) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// 不阻塞地取 n 个令牌，令牌不足时返回 false
+func (l *RateLimiter) AllowN(n int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate <= 0 {
+		return true
+	}
+	l.advance(time.Now())
+	if l.tokens < float64(n) {
+		return false
+	}
+	l.tokens -= float64(n)
+	return true
+}
+
+func (l *RateLimiter) waitN(n int, stop1, stop2 <-chan struct{}) bool {
+	l.mu.Lock()
+	if l.rate <= 0 {
+		l.mu.Unlock()
+		return true
+	}
+	// 先预定令牌，令牌数可为负，按欠的数量计算等待时间
+	l.advance(time.Now())
+	l.tokens -= float64(n)
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return true
+	}
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-stop1:
+	case <-stop2:
+	}
+
+	// 放弃时归还预定的令牌
+	l.mu.Lock()
+	l.advance(time.Now())
+	l.tokens += float64(n)
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+	l.mu.Unlock()
+	return false
+}
+
+// burst 不大于0时取 tps 向上取整，至少为1
+func defaultBurst(tps float64, burst int) int {
+	if burst <= 0 {
+		burst = int(tps)
+		if float64(burst) < tps || burst == 0 {
+			burst++
+		}
+	}
+	return burst
+}
+
+func (l *RateLimiter) advance(now time.Time) {
+	elapsed := now.Sub(l.last)
+	l.last = now
+	if elapsed <= 0 || l.rate <= 0 {
+		return
+	}
+	l.tokens += elapsed.Seconds() * l.rate
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+}
+
+type accountLimiter struct {
+	*RateLimiter
+	refs int
+}
+
+var (
+	accountLimitersMu sync.Mutex
+	accountLimiters   = make(map[string]*accountLimiter)
+)
+
+// 取 clientID 对应的限速器，同一账号的所有连接共用。
+// 已存在时保留最先的速率，参数不同时返回 ErrLimiterConflict；
+// 每次成功调用都须对应一次 ReleaseAccountLimiter，Client 在 Disconnect 时自动释放
+func AccountLimiter(clientID string, tps float64, burst int) (*RateLimiter, error) {
+	accountLimitersMu.Lock()
+	defer accountLimitersMu.Unlock()
+
+	l, ok := accountLimiters[clientID]
+	if !ok {
+		l = &accountLimiter{RateLimiter: NewRateLimiter(tps, burst)}
+		accountLimiters[clientID] = l
+	} else if rate, b := l.Rate(); rate != tps || b != defaultBurst(tps, burst) {
+		return nil, ErrLimiterConflict
+	}
+	l.refs++
+	return l.RateLimiter, nil
+}
+
+// 释放一次 AccountLimiter 取得的限速器，没有连接使用时删除
+func ReleaseAccountLimiter(clientID string) {
+	accountLimitersMu.Lock()
+	defer accountLimitersMu.Unlock()
+
+	l, ok := accountLimiters[clientID]
+	if !ok {
+		return
+	}
+	if l.refs--; l.refs <= 0 {
+		delete(accountLimiters, clientID)
+	}
+}
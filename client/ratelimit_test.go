@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/boxtsecond/gosmgp/pkg"
+)
+
+func TestRateLimiterBurstAndRate(t *testing.T) {
+	tests := []struct {
+		tps   float64
+		burst int
+		n     int
+		min   time.Duration
+		max   time.Duration
+	}{
+		// 突发内不等待
+		{tps: 10, burst: 5, n: 5, min: 0, max: 50 * time.Millisecond},
+		// 超出突发后按 tps 放行：5 个令牌各 50ms
+		{tps: 20, burst: 5, n: 10, min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		// burst 为 0 时取 tps
+		{tps: 4, burst: 0, n: 4, min: 0, max: 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		l := NewRateLimiter(tt.tps, tt.burst)
+		start := time.Now()
+		for i := 0; i < tt.n; i++ {
+			if err := l.Wait(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if el := time.Since(start); el < tt.min || el > tt.max {
+			t.Errorf("tps %v burst %d: %d waits took %v, want %v-%v", tt.tps, tt.burst, tt.n, el, tt.min, tt.max)
+		}
+	}
+}
+
+func TestRateLimiterCancelRefunds(t *testing.T) {
+	l := NewRateLimiter(10, 2)
+	if !l.AllowN(2) {
+		t.Fatal("AllowN on a full bucket failed")
+	}
+	if l.AllowN(1) {
+		t.Fatal("AllowN on an empty bucket succeeded")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.WaitN(ctx, 5); err != context.DeadlineExceeded {
+		t.Fatalf("WaitN error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// 取消的预定已归还，200ms 后桶应重新满
+	time.Sleep(220 * time.Millisecond)
+	if !l.AllowN(2) {
+		t.Error("tokens reserved by a cancelled WaitN were not returned")
+	}
+}
+
+func TestAccountLimiterShared(t *testing.T) {
+	a, err := AccountLimiter("test-shared", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := AccountLimiter("test-shared", 10, 10); err != nil || a != b {
+		t.Errorf("same clientID returned %p, %v, want %p", b, err, a)
+	}
+	if c, err := AccountLimiter("test-other", 10, 0); err != nil || a == c {
+		t.Errorf("different clientIDs share a limiter: %v", err)
+	}
+
+	// 参数不同时保留最先的速率
+	for _, r := range []struct {
+		tps   float64
+		burst int
+	}{{20, 0}, {10, 4}} {
+		if l, err := AccountLimiter("test-shared", r.tps, r.burst); err != ErrLimiterConflict || l != nil {
+			t.Errorf("AccountLimiter(%v, %d) = %p, %v, want %v", r.tps, r.burst, l, err, ErrLimiterConflict)
+		}
+	}
+	if tps, burst := a.Rate(); tps != 10 || burst != 10 {
+		t.Errorf("rate = %v/%d, want 10/10", tps, burst)
+	}
+
+	// 两次取得须释放两次才删除
+	ReleaseAccountLimiter("test-shared")
+	if l, _ := AccountLimiter("test-shared", 10, 0); l != a {
+		t.Error("limiter removed while still referenced")
+	}
+	for i := 0; i < 3; i++ {
+		ReleaseAccountLimiter("test-shared")
+	}
+	if l, err := AccountLimiter("test-shared", 20, 4); err != nil || l == a {
+		t.Errorf("released limiter reused: %v", err)
+	}
+	ReleaseAccountLimiter("test-shared")
+	ReleaseAccountLimiter("test-other")
+}
+
+func TestClientReleasesAccountLimiter(t *testing.T) {
+	addr := fakeGateway(t, echoSubmit)
+	c1 := dialAccount(t, addr, "release-tps", 10, 1)
+
+	c2 := NewClient(pkg.VERSION)
+	c2.TPS = 20
+	if err := c2.Connect(addr, "release-tps", "secret", 0, time.Second); err != ErrLimiterConflict {
+		t.Fatalf("Connect with another TPS error = %v, want %v", err, ErrLimiterConflict)
+	}
+
+	c1.Disconnect()
+	c2 = dialAccount(t, addr, "release-tps", 20, 1)
+	defer c2.Disconnect()
+	if tps, _ := c2.Limiter().Rate(); tps != 20 {
+		t.Errorf("rate after release = %v, want 20", tps)
+	}
+
+	// AsyncClient 连接断开时同样释放
+	a := NewAsyncClient(dialAccount(t, addr, "release-async", 10, 1), time.Second)
+	a.Close()
+	c3 := dialAccount(t, addr, "release-async", 5, 1)
+	c3.Disconnect()
+}
+
+func TestAsyncClientTPS(t *testing.T) {
+	// 6 条 Submit，TPS=10、burst=1，第一条不等待，其余各 100ms
+	tests := []struct {
+		name     string
+		clientID string
+		window   int
+	}{
+		{"no window", "tps-nw", 0},
+		{"window", "tps-w", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := fakeGateway(t, echoSubmit)
+			a := NewAsyncClient(dialAccount(t, addr, tt.clientID, 10, 1), time.Second)
+			defer a.Close()
+			if tt.window > 0 {
+				a.Window = NewWindow(tt.window)
+			}
+
+			start := time.Now()
+			for j := 0; j < 6; j++ {
+				if _, err := a.Submit(context.Background(), testSubmit("ok")); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if el := time.Since(start); el < 450*time.Millisecond || el > 800*time.Millisecond {
+				t.Errorf("6 submits took %v, want about 500ms", el)
+			}
+		})
+	}
+}
+
+func TestAsyncClientTPSSharedByAccount(t *testing.T) {
+	addr := fakeGateway(t, echoSubmit)
+	c1 := dialAccount(t, addr, "shared-tps", 10, 1)
+	c2 := dialAccount(t, addr, "shared-tps", 10, 1)
+	if c1.Limiter() == nil || c1.Limiter() != c2.Limiter() {
+		t.Fatal("clients of the same account do not share a limiter")
+	}
+
+	a1, a2 := NewAsyncClient(c1, time.Second), NewAsyncClient(c2, time.Second)
+	defer a1.Close()
+	defer a2.Close()
+
+	// 两个连接各发 3 条，共 6 条，合计仍受 10 TPS 限制
+	start := time.Now()
+	errs := make(chan error, 6)
+	for _, a := range []*AsyncClient{a1, a2} {
+		go func(a *AsyncClient) {
+			for j := 0; j < 3; j++ {
+				_, err := a.Submit(context.Background(), testSubmit("ok"))
+				errs <- err
+			}
+		}(a)
+	}
+	for j := 0; j < 6; j++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if el := time.Since(start); el < 450*time.Millisecond {
+		t.Errorf("6 submits over two connections took %v, want >= 500ms", el)
+	}
+}
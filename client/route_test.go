@@ -0,0 +1,90 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/boxtsecond/gosmgp/pkg"
+)
+
+// 按 pages 依次应答 GET_ALL 请求，pages 用完后应答空页
+func routePager(pages [][]uint32) func(c *pkg.Conn, h *pkg.Header, p pkg.Packer) {
+	n := 0
+	next := func() []uint32 {
+		if n >= len(pages) {
+			return nil
+		}
+		n++
+		return pages[n-1]
+	}
+	return func(c *pkg.Conn, h *pkg.Header, p pkg.Packer) {
+		switch p.(type) {
+		case *pkg.SmgpGetAllTERouteReqPkt:
+			rsp := &pkg.SmgpGetAllTERouteRespPkt{}
+			for _, id := range next() {
+				rsp.Routes = append(rsp.Routes, pkg.TERoute{RouteID: id})
+			}
+			c.SendPkt(rsp, h.SequenceID)
+		case *pkg.SmgpGetAllSPRouteReqPkt:
+			rsp := &pkg.SmgpGetAllSPRouteRespPkt{}
+			for _, id := range next() {
+				rsp.Routes = append(rsp.Routes, pkg.SPRoute{RouteID: id})
+			}
+			c.SendPkt(rsp, h.SequenceID)
+		}
+	}
+}
+
+func TestRouteIterators(t *testing.T) {
+	tests := []struct {
+		name  string
+		pages [][]uint32
+		ids   []uint32
+		err   error
+	}{
+		{"paged", [][]uint32{{1, 2}, {3, 4}, {5}}, []uint32{1, 2, 3, 4, 5}, nil},
+		{"empty", nil, nil, nil},
+		{"route id 0 first", [][]uint32{{0}, {1}}, []uint32{0, 1}, nil},
+		{"repeated page", [][]uint32{{1, 2}, {1, 2}, {1, 2}}, []uint32{1, 2}, ErrRoutePageRepeat},
+		{"decreasing id", [][]uint32{{5, 6}, {3, 4}}, []uint32{5, 6}, ErrRoutePageRepeat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"/TE", func(t *testing.T) {
+			cli := dialAccount(t, fakeGateway(t, routePager(tt.pages)), "10000001", 0, 0)
+			defer cli.Disconnect()
+
+			var ids []uint32
+			it := cli.TERoutes("010061", time.Second)
+			for it.Next() {
+				ids = append(ids, it.Route().RouteID)
+			}
+			checkRouteWalk(t, ids, it.Err(), tt.ids, tt.err)
+		})
+		t.Run(tt.name+"/SP", func(t *testing.T) {
+			cli := dialAccount(t, fakeGateway(t, routePager(tt.pages)), "10000001", 0, 0)
+			defer cli.Disconnect()
+
+			var ids []uint32
+			it := cli.SPRoutes("010061", time.Second)
+			for it.Next() {
+				ids = append(ids, it.Route().RouteID)
+			}
+			checkRouteWalk(t, ids, it.Err(), tt.ids, tt.err)
+		})
+	}
+}
+
+func checkRouteWalk(t *testing.T, ids []uint32, err error, wantIDs []uint32, wantErr error) {
+	t.Helper()
+	if err != wantErr {
+		t.Errorf("Err() = %v, want %v", err, wantErr)
+	}
+	if len(ids) != len(wantIDs) {
+		t.Fatalf("routes = %v, want %v", ids, wantIDs)
+	}
+	for i := range ids {
+		if ids[i] != wantIDs[i] {
+			t.Fatalf("routes = %v, want %v", ids, wantIDs)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// 滑动窗口，限制已发出但未收到应答的请求数，可并发使用
+type Window struct {
+	slots   chan struct{}
+	waiting int32
+}
+
+func NewWindow(size int) *Window {
+	if size <= 0 {
+		size = 1
+	}
+	return &Window{slots: make(chan struct{}, size)}
+}
+
+// 占用一个位置，窗口已满时阻塞，直到有位置释放或 ctx 结束
+func (w *Window) Acquire(ctx context.Context) error {
+	if !w.acquire(ctx.Done(), nil) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// 占用一个位置，任一 stop 关闭时放弃并返回 false
+func (w *Window) acquire(stop1, stop2 <-chan struct{}) bool {
+	if w.TryAcquire() {
+		return true
+	}
+
+	atomic.AddInt32(&w.waiting, 1)
+	defer atomic.AddInt32(&w.waiting, -1)
+	select {
+	case w.slots <- struct{}{}:
+		return true
+	case <-stop1:
+		return false
+	case <-stop2:
+		return false
+	}
+}
+
+// 不阻塞地占用一个位置，窗口已满时返回 false
+func (w *Window) TryAcquire() bool {
+	select {
+	case w.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// 释放一个位置
+func (w *Window) Release() {
+	select {
+	case <-w.slots:
+	default:
+	}
+}
+
+// 窗口大小
+func (w *Window) Size() int {
+	return cap(w.slots)
+}
+
+// 已占用的位置数
+func (w *Window) InUse() int {
+	return len(w.slots)
+}
+
+// 等待位置的请求数
+func (w *Window) Waiting() int {
+	return int(atomic.LoadInt32(&w.waiting))
+}
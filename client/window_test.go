@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/boxtsecond/gosmgp/pkg"
+)
+
+func TestWindow(t *testing.T) {
+	w := NewWindow(2)
+	if !w.TryAcquire() || !w.TryAcquire() {
+		t.Fatal("TryAcquire failed on a free window")
+	}
+	if w.TryAcquire() {
+		t.Fatal("TryAcquire succeeded on a full window")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := w.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Acquire on a full window error = %v", err)
+	}
+	if n := w.Waiting(); n != 0 {
+		t.Fatalf("waiting after cancel = %d, want 0", n)
+	}
+
+	w.Release()
+	if err := w.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	w.Release()
+	w.Release()
+	if n := w.InUse(); n != 0 {
+		t.Fatalf("in use = %d, want 0", n)
+	}
+}
+
+func TestAsyncClientWindowLimitsInFlight(t *testing.T) {
+	var mu sync.Mutex
+	inflight, peak := 0, 0
+	addr := fakeGateway(t, func(c *pkg.Conn, h *pkg.Header, p pkg.Packer) {
+		if _, ok := p.(*pkg.SmgpSubmitReqPkt); !ok {
+			return
+		}
+		mu.Lock()
+		if inflight++; inflight > peak {
+			peak = inflight
+		}
+		mu.Unlock()
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			inflight--
+			mu.Unlock()
+			echoSubmit(c, h, p)
+		}()
+	})
+	a := newAsync(t, addr, 2*time.Second)
+	a.Window = NewWindow(3)
+	ctx := context.Background()
+
+	var fs []SubmitFuture
+	for i := 0; i < 8; i++ {
+		fs = append(fs, a.SubmitAsync(testSubmit("ok")))
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := a.Submit(ctx, testSubmit("ok")); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	for _, f := range fs {
+		if _, err := f.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if peak > 3 {
+		t.Errorf("peak in flight = %d, want <= 3", peak)
+	}
+	if n := a.Window.InUse(); n != 0 {
+		t.Errorf("slots in use after responses = %d, want 0", n)
+	}
+}
+
+func TestAsyncClientWindowReleasedOnTimeout(t *testing.T) {
+	addr := fakeGateway(t, echoSubmit)
+	a := newAsync(t, addr, 30*time.Millisecond)
+	a.Window = NewWindow(2)
+	ctx := context.Background()
+
+	// 占满窗口的请求超时后，排队的请求应能发出
+	fs := []SubmitFuture{
+		a.SubmitAsync(testSubmit("drop")),
+		a.SubmitAsync(testSubmit("drop")),
+	}
+	if _, err := a.Submit(ctx, testSubmit("ok")); err != nil {
+		t.Fatalf("queued Submit error = %v", err)
+	}
+	for _, f := range fs {
+		if _, err := f.Wait(ctx); err != ErrRequestTimeout {
+			t.Errorf("dropped request error = %v, want %v", err, ErrRequestTimeout)
+		}
+	}
+	if n := a.Window.InUse(); n != 0 {
+		t.Errorf("slots in use after timeout = %d, want 0", n)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -28,142 +29,79 @@ var (
 func startAClient(idx int) {
 	c := client.NewClient(pkg.VERSION)
 	defer wg.Done()
-	defer c.Disconnect()
 
 	mode, _ := strconv.Atoi(*loginMode)
 	err := c.Connect(*addr, *clientID, *secret, uint8(mode), 3*time.Second)
 	if err != nil {
 		log.Printf("client %d: connect error: %s.", idx, err)
+		c.Disconnect()
 		return
 	}
 	log.Printf("client %d: connect and auth ok", idx)
 
-	t := time.NewTicker(time.Second)
-	defer t.Stop()
-	maxSubmit := 1
-	count := 0
-	for {
-		select {
-		case <-t.C:
-			if count >= maxSubmit {
-				continue
-			}
-
-			cont, err := pkg.Utf8ToGB18030(*msg)
-			if err != nil {
-				fmt.Printf("client %d: utf8 to gb18030 transform err: %s.", idx, err)
-				return
-			}
-			destStrArr := strings.Split(*phone, ",")
-
-			p := &pkg.SmgpSubmitReqPkt{
-				MsgType:         pkg.MT,
-				NeedReport:      pkg.NEED_REPORT,
-				Priority:        pkg.NORMAL_PRIORITY,
-				ServiceID:       "",
-				FeeType:         "00",
-				FeeCode:         "0",
-				FixedFee:        "0",
-				MsgFormat:       pkg.GB18030,
-				ValidTime:       "",
-				AtTime:          "",
-				SrcTermID:       *spCode,
-				ChargeTermID:    "",
-				DestTermIDCount: uint8(len(destStrArr)),
-				DestTermID:      destStrArr,
-				MsgLength:       uint8(len(cont)),
-				MsgContent:      cont,
-				Reserve:         "",
-				//Options: pkg.Options{
-				//	pkg.TAG_PkTotal:  pkg.NewTLV(pkg.TAG_PkTotal, []byte{1}),
-				//	pkg.TAG_PkNumber: pkg.NewTLV(pkg.TAG_PkNumber, []byte{uint8(1)}),
-				//	pkg.TAG_TP_udhi:  pkg.NewTLV(pkg.TAG_TP_udhi, []byte{0}),
-				//	pkg.TAG_TP_pid:   pkg.NewTLV(pkg.TAG_TP_pid, []byte{1}),
-				//},
-			}
-			pkgs := make([]*pkg.SmgpSubmitReqPkt, 0)
-
-			if len(cont) > 140 {
-				pkgs, err = pkg.GetLongMsgPkgs(p)
-				if err != nil {
-					log.Printf("client %d: get long msg pkg error: %s.", idx, err)
-					continue
-				}
-			} else {
-				p.Options = pkg.Options{
-					pkg.TAG_PkTotal:  pkg.NewTLV(pkg.TAG_PkTotal, []byte{1}),
-					pkg.TAG_PkNumber: pkg.NewTLV(pkg.TAG_PkNumber, []byte{uint8(1)}),
-					pkg.TAG_TP_udhi:  pkg.NewTLV(pkg.TAG_TP_udhi, []byte{0}),
-					pkg.TAG_TP_pid:   pkg.NewTLV(pkg.TAG_TP_pid, []byte{1}),
-				}
-				pkgs = append(pkgs, p)
-			}
-
-			for _, req := range pkgs {
-				_, err = c.SendReqPkt(req)
-			}
-			if err != nil {
-				log.Printf("client %d: send a smgp submit request error: %s.", idx, err)
-				return
-			} else {
-				log.Printf("client %d: send a smgp submit request ok", idx)
-			}
-			count += 1
-		default:
-		}
-
-		// recv packets
-		i, err := c.RecvAndUnpackPkt(0)
-		if err != nil {
-			log.Printf("client %d: client read and unpack pkt error: %s.", idx, err)
-			break
-		}
-
-		switch p := i.(type) {
-		case *pkg.SmgpSubmitRespPkt:
-			log.Printf("client %d: receive a smgp submit response: \n%v", idx, p)
-
-		case *pkg.SmgpDeliverReqPkt:
+	a := client.NewAsyncClient(c, 10*time.Second)
+	defer a.Close()
+	a.Handler = func(h *pkg.Header, req pkg.Packer) pkg.Packer {
+		if p, ok := req.(*pkg.SmgpDeliverReqPkt); ok {
 			log.Printf("client %d: receive a smgp deliver request: \n%v", idx, p)
-			if p.IsReport == 1 {
+			if p.IsReport == pkg.IS_REPORT {
 				log.Printf("client %d: the smgp deliver request: %s is a status report.", idx, p.MsgID)
+				if r, err := p.Report(); err != nil {
+					log.Printf("client %d: decode status report error: %s.", idx, err)
+				} else if r.Stat.IsFinal() {
+					log.Printf("client %d: msg %s final state: %s(%s), success: %v.", idx, r.SubmitMsgID, r.Stat, r.Stat.Description(), r.Stat.IsSuccess())
+				}
 			}
-			rsp := &pkg.SmgpDeliverRespPkt{
-				MsgID:  p.MsgID,
-				Status: pkg.Status(0),
-			}
-
-			err := c.SendRspPkt(rsp, p.SequenceID)
-			if err != nil {
-				log.Printf("client %d: send smgp deliver response error: %s.", idx, err)
-				break
-			} else {
-				log.Printf("client %d: send smgp deliver response ok.", idx)
-			}
+		} else {
+			log.Printf("client %d: receive a %v.", idx, pkg.RequestID(h.RequestID))
+		}
+		rsp, _ := pkg.NewResponse(pkg.RequestID(h.RequestID), req, h.SequenceID)
+		return rsp
+	}
+	a.ErrorHandler = func(err error) {
+		log.Printf("client %d: %s.", idx, err)
+	}
+	a.StartKeepAlive(30*time.Second, 3)
 
-		case *pkg.SmgpActiveTestReqPkt:
-			log.Printf("client %d: receive a smgp active request.", idx)
-			rsp := &pkg.SmgpActiveTestRespPkt{}
-			err := c.SendRspPkt(rsp, p.SequenceID)
-			if err != nil {
-				log.Printf("client %d: send smgp active response error: %s.", idx, err)
-				break
-			}
-		case *pkg.SmgpActiveTestRespPkt:
-			log.Printf("client %d: receive a smgp active response.", idx)
+	text, err := pkg.EncodeText(*msg, pkg.ENCODE_AUTO)
+	if err != nil {
+		fmt.Printf("client %d: encode msg err: %s.", idx, err)
+		return
+	}
+	destStrArr := strings.Split(*phone, ",")
+
+	p := &pkg.SmgpSubmitReqPkt{
+		MsgType:         pkg.MT,
+		NeedReport:      pkg.NEED_REPORT,
+		Priority:        pkg.NORMAL_PRIORITY,
+		ServiceID:       "",
+		FeeType:         "00",
+		FeeCode:         "0",
+		FixedFee:        "0",
+		ValidTime:       "",
+		AtTime:          "",
+		SrcTermID:       *spCode,
+		ChargeTermID:    "",
+		DestTermIDCount: uint8(len(destStrArr)),
+		DestTermID:      destStrArr,
+		Reserve:         "",
+	}
+	p.Options.SetTPPid(1)
+	pkgs := text.Submits(p)
+	log.Printf("client %d: msg format %d, %d segments.", idx, text.MsgFormat, len(pkgs))
 
-		case *pkg.SmgpExitReqPkt:
-			log.Printf("client %d: receive a smgp exit request.", idx)
-			rsp := &pkg.SmgpExitRespPkt{}
-			err := c.SendRspPkt(rsp, p.SequenceID)
-			if err != nil {
-				log.Printf("client %d: send smgp exit response error: %s.", idx, err)
-				break
-			}
-		case *pkg.SmgpExitRespPkt:
-			log.Printf("client %d: receive a smgp exit response.", idx)
+	for _, req := range pkgs {
+		rsp, err := a.Submit(context.Background(), req)
+		if err != nil {
+			log.Printf("client %d: send a smgp submit request error: %s.", idx, err)
+			return
 		}
+		log.Printf("client %d: receive a smgp submit response: \n%v", idx, rsp)
 	}
+
+	// 继续接收状态报告，直到连接断开
+	<-a.Done()
+	log.Printf("client %d: connection closed: %s.", idx, a.Err())
 }
 
 var wg sync.WaitGroup
@@ -27,7 +27,7 @@ func handleLogin(r *server.Response, p *server.Packet, l *log.Logger) (bool, err
 
 	resp.ServerVersion = pkg.VERSION
 	if req.ClientID != string(pkg.NewOctetString(user).Byte(8)) {
-		resp.Status = pkg.Status(21)
+		resp.Status = pkg.STAT_AUTH_ERR
 		l.Println("handleLogin ClientID error:", resp.Status.Error())
 		return false, resp.Status.Error()
 	}
@@ -35,14 +35,14 @@ func handleLogin(r *server.Response, p *server.Packet, l *log.Logger) (bool, err
 	tm := req.TimeStamp
 	auth, err := pkg.GenAuthenticatorClient(req.ClientID, password, tm)
 	if err != nil || req.AuthenticatorClient != string(auth[:]) {
-		resp.Status = pkg.Status(21)
+		resp.Status = pkg.STAT_AUTH_ERR
 		l.Println("handleLogin auth GenAuthenticatorClient error:", resp.Status.Error())
 		return false, resp.Status.Error()
 	}
 
 	authServer, err := pkg.GenAuthenticatorServer(resp.Status, password, string(auth[:]))
 	if err != nil {
-		resp.Status = pkg.Status(21)
+		resp.Status = pkg.STAT_AUTH_ERR
 		l.Println("handleLogin GenAuthenticatorServer error:", resp.Status.Error())
 		return false, resp.Status.Error()
 	}
@@ -60,39 +60,28 @@ func handleSubmit(r *server.Response, p *server.Packet, l *log.Logger) (bool, er
 	}
 
 	resp := r.Packer.(*pkg.SmgpSubmitRespPkt)
-	resp.MsgID, _ = pkg.GenMsgID(spId, <-p.Conn.SequenceNum)
+	if err := req.Validate(); err != nil {
+		resp.Status = pkg.ErrorStatus(err)
+		l.Println("handleSubmit validate error:", err)
+		return false, nil
+	}
+	// 已由计费鉴权等前置 Handler 生成时沿用
+	if resp.MsgID.IsZero() {
+		resp.MsgID, _ = pkg.GenMsgID(spId, <-p.Conn.SequenceNum)
+	}
 	deliverPkgs := make([]*pkg.SmgpDeliverReqPkt, 0)
 	for i, d := range req.DestTermID {
 		l.Printf("handleSubmit: handle submit from %s ok! msgid[%s], destTerminalId[%s]\n",
 			req.SrcTermID, fmt.Sprintf("%s_%d", resp.MsgID, i), d)
-		t := pkg.GenNowTimeYYStr()
-		msgStat := pkg.SmgpDeliverMsgContent{
-			SubmitMsgID: resp.MsgID,
-			Sub:         "001",
-			Dlvrd:       "001",
-			SubmitDate:  t,
-			DoneDate:    t,
-			Stat:        "DELIVRD",
-			Err:         "000",
-			Txt:         "00000000000000000000",
+		now := time.Now()
+		report := pkg.NewReport(resp.MsgID, pkg.REPORT_DELIVRD, pkg.REPORT_ERR_OK, now, now, req.MsgContent)
+		deliver := pkg.NewReportDeliver(resp.MsgID, report, d, req.SrcTermID)
+		deliver.SequenceID = <-p.Conn.SequenceID
+		deliver.Options = pkg.Options{
+			pkg.TAG_TP_udhi: pkg.NewTLV(pkg.TAG_TP_udhi, []byte{0}),
+			pkg.TAG_TP_pid:  pkg.NewTLV(pkg.TAG_TP_pid, []byte{1}),
 		}
-		msgContent := msgStat.Encode()
-		deliverPkgs = append(deliverPkgs, &pkg.SmgpDeliverReqPkt{
-			MsgID:      resp.MsgID,
-			IsReport:   pkg.IS_REPORT,
-			MsgFormat:  pkg.ASCII,
-			RecvTime:   pkg.GenNowTimeYYYYStr(),
-			SrcTermID:  d,
-			DestTermID: req.SrcTermID,
-			MsgLength:  uint8(len(msgContent)),
-			MsgContent: []byte(msgContent),
-			Reserve:    "",
-			SequenceID: <-p.Conn.SequenceID,
-			Options: pkg.Options{
-				pkg.TAG_TP_udhi: pkg.NewTLV(pkg.TAG_TP_udhi, []byte{0}),
-				pkg.TAG_TP_pid:  pkg.NewTLV(pkg.TAG_TP_pid, []byte{1}),
-			},
-		})
+		deliverPkgs = append(deliverPkgs, deliver)
 	}
 	go mockDeliver(deliverPkgs, p)
 	return false, nil
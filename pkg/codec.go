@@ -0,0 +1,187 @@
+package pkg
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// 解码帧时出现的错误，Err 为具体原因，
+// 如 ErrTotalLengthInvalid、ErrRequestIDInvalid、ErrRequestIDNotSupported、
+// io.ErrUnexpectedEOF 或包体解析错误
+type FrameError struct {
+	Header Header // 包头未完整读出时为零值
+	Err    error
+}
+
+func (e *FrameError) Error() string {
+	return fmt.Sprintf("smgp frame(%v, length: %d, seq: %d) error: %v",
+		RequestID(e.Header.RequestID), e.Header.PacketLength, e.Header.SequenceID, e.Err)
+}
+
+func (e *FrameError) Unwrap() error {
+	return e.Err
+}
+
+func (e *FrameError) Cause() error {
+	return e.Err
+}
+
+// 校验包头中的包长度
+func checkPacketLength(h *Header, max uint32) error {
+	if h.PacketLength < SMGP_PACKET_MIN || h.PacketLength > max {
+		return ErrTotalLengthInvalid
+	}
+	return nil
+}
+
+// 根据包头创建包并解析包体，returnRaw 为 true 时未知命令以 *RawPkt 返回
+func unpackFrame(h Header, body []byte, returnRaw bool) (Packer, error) {
+	var (
+		p   Packer
+		err error
+	)
+	if !RequestID(h.RequestID).Valid() {
+		err = ErrRequestIDInvalid
+	} else {
+		p, err = NewPDU(RequestID(h.RequestID), h.SequenceID)
+	}
+	if err != nil {
+		if !returnRaw {
+			return nil, err
+		}
+		p = &RawPkt{Header: h, SequenceID: h.SequenceID}
+	}
+
+	err = p.Unpack(body)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// 从任意 io.Reader 中按帧解码 SMGP 包，不依赖 net.Conn
+type Decoder struct {
+	r io.Reader
+
+	// 允许的最大包长度，为0时使用 SMGP_PACKET_MAX
+	MaxPacketLength uint32
+	// 为 true 时，未知或未登记编解码的命令以 *RawPkt 返回
+	ReturnRawPkt bool
+
+	hbuf [HeaderPktLen]byte
+	buf  []byte
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r: r,
+	}
+}
+
+func (d *Decoder) maxPacketLength() uint32 {
+	if d.MaxPacketLength == 0 {
+		return SMGP_PACKET_MAX
+	}
+	return d.MaxPacketLength
+}
+
+// 读取并解码一个完整的包。
+// 流在帧边界处结束时返回 io.EOF，其余错误均为 *FrameError。
+// 包长度合法时包体已被完整读出，出错后仍可继续 Decode。
+func (d *Decoder) Decode() (Packer, error) {
+	_, p, err := d.DecodeFrame()
+	return p, err
+}
+
+// 同 Decode，同时返回包头
+func (d *Decoder) DecodeFrame() (*Header, Packer, error) {
+	var h Header
+
+	// packet header
+	n, err := io.ReadFull(d.r, d.hbuf[:])
+	if err != nil {
+		if err == io.EOF && n == 0 {
+			return nil, nil, io.EOF
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, nil, &FrameError{Err: err}
+	}
+	h.PacketLength = binary.BigEndian.Uint32(d.hbuf[0:4])
+	h.RequestID = binary.BigEndian.Uint32(d.hbuf[4:8])
+	h.SequenceID = binary.BigEndian.Uint32(d.hbuf[8:12])
+
+	if err = checkPacketLength(&h, d.maxPacketLength()); err != nil {
+		return &h, nil, &FrameError{Header: h, Err: err}
+	}
+
+	// packet body
+	bodyLen := int(h.PacketLength - HeaderPktLen)
+	if cap(d.buf) < bodyLen {
+		d.buf = make([]byte, bodyLen)
+	}
+	body := d.buf[:bodyLen]
+	if _, err = io.ReadFull(d.r, body); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return &h, nil, &FrameError{Header: h, Err: err}
+	}
+
+	p, err := unpackFrame(h, body, d.ReturnRawPkt)
+	if err != nil {
+		return &h, nil, &FrameError{Header: h, Err: err}
+	}
+	return &h, p, nil
+}
+
+// 从字节切片中解码第一个包，n 为该包占用的字节数。
+// 数据不足一个完整包时返回 ErrIncompleteFrame，n 为0，可在追加数据后重试；
+// 包长度合法但解析失败时 n 仍为包长度，调用方可跳过该包继续解码。
+func DecodeBytes(data []byte) (Packer, int, error) {
+	var h Header
+	if len(data) < int(HeaderPktLen) {
+		return nil, 0, ErrIncompleteFrame
+	}
+	h.PacketLength = binary.BigEndian.Uint32(data[0:4])
+	h.RequestID = binary.BigEndian.Uint32(data[4:8])
+	h.SequenceID = binary.BigEndian.Uint32(data[8:12])
+
+	if err := checkPacketLength(&h, SMGP_PACKET_MAX); err != nil {
+		return nil, 0, &FrameError{Header: h, Err: err}
+	}
+
+	n := int(h.PacketLength)
+	if len(data) < n {
+		return nil, 0, ErrIncompleteFrame
+	}
+
+	p, err := unpackFrame(h, data[HeaderPktLen:n], false)
+	if err != nil {
+		return nil, n, &FrameError{Header: h, Err: err}
+	}
+	return p, n, nil
+}
+
+// 向任意 io.Writer 写入 SMGP 包
+type Encoder struct {
+	w io.Writer
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w: w,
+	}
+}
+
+func (e *Encoder) Encode(p Packer, seqId uint32) error {
+	data, err := p.Pack(seqId)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(data)
+	return err
+}
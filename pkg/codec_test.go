@@ -0,0 +1,64 @@
+package pkg
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+)
+
+func frame(id RequestID, body []byte) []byte {
+	b := make([]byte, int(HeaderPktLen)+len(body))
+	binary.BigEndian.PutUint32(b[0:4], uint32(len(b)))
+	binary.BigEndian.PutUint32(b[4:8], uint32(id))
+	binary.BigEndian.PutUint32(b[8:12], 1)
+	copy(b[HeaderPktLen:], body)
+	return b
+}
+
+// 截断的包体只能返回错误，不能 panic
+func decodeNoPanic(t *testing.T, id RequestID, data []byte) {
+	defer func() {
+		if e := recover(); e != nil {
+			t.Errorf("%v: DecodeBytes panics on %d-byte body: %v", id, len(data)-int(HeaderPktLen), e)
+		}
+	}()
+	if _, n, err := DecodeBytes(data); err == nil && n != len(data) {
+		t.Errorf("%v: DecodeBytes consumed %d of %d bytes", id, n, len(data))
+	}
+}
+
+func TestDecodeBytesTruncatedBody(t *testing.T) {
+	for id, factory := range pduFactories {
+		// 用完整的包体逐字节截断，包体取不到时用全 0 的包体
+		full := make([]byte, 256)
+		if b, err := factory(1).Pack(1); err == nil {
+			full = b[HeaderPktLen:]
+		}
+		for n := 0; n < len(full); n++ {
+			decodeNoPanic(t, id, frame(id, full[:n]))
+		}
+		// MsgLength 等长度字段大于实际内容
+		for n := 0; n < 200; n++ {
+			body := make([]byte, n)
+			for i := range body {
+				body[i] = 0xff
+			}
+			decodeNoPanic(t, id, frame(id, body))
+		}
+	}
+}
+
+func TestDecodeBytesSubmitShortBody(t *testing.T) {
+	tests := []int{0, 1, 60, 104, 113}
+	for _, n := range tests {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			_, consumed, err := DecodeBytes(frame(SMGP_SUBMIT, make([]byte, n)))
+			if err == nil {
+				t.Fatal("short submit body decoded without error")
+			}
+			if consumed != int(HeaderPktLen)+n {
+				t.Errorf("consumed = %d, want %d", consumed, int(HeaderPktLen)+n)
+			}
+		})
+	}
+}
@@ -22,6 +22,13 @@ type Conn struct {
 	State   State
 	Version uint8
 
+	// 为 true 时，未知或未登记编解码的命令以 *RawPkt 返回，
+	// 而不是返回 ErrRequestIDInvalid 或 ErrRequestIDNotSupported
+	ReturnRawPkt bool
+
+	// 长短信参考号分配器，供 Splitter 使用
+	ConcatRefs *RefAllocator
+
 	// for SequenceID generator goroutine
 	SequenceID <-chan uint32
 	done       chan<- struct{}
@@ -78,6 +85,7 @@ func NewConnection(conn net.Conn, v uint8) *Conn {
 		Version:     v,
 		SequenceID:  sequenceID,
 		SequenceNum: sequenceNum,
+		ConcatRefs:  NewRefAllocator(),
 		done:        done,
 		numDone:     numDone,
 	}
@@ -136,8 +144,14 @@ var readBufferPool = sync.Pool{
 }
 
 func (c *Conn) RecvAndUnpackPkt(timeout time.Duration) (Packer, error) {
+	_, p, err := c.RecvPkt(timeout)
+	return p, err
+}
+
+// 同 RecvAndUnpackPkt，同时返回包头
+func (c *Conn) RecvPkt(timeout time.Duration) (*Header, Packer, error) {
 	if c.State == CONNECTION_CLOSED {
-		return nil, ErrConnIsClosed
+		return nil, nil, ErrConnIsClosed
 	}
 	rb := readBufferPool.Get().(*readBuffer)
 	defer func() {
@@ -152,16 +166,11 @@ func (c *Conn) RecvAndUnpackPkt(timeout time.Duration) (Packer, error) {
 	// packet header
 	err := binary.Read(c.Conn, binary.BigEndian, &rb.Header)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	if rb.Header.PacketLength < SMGP_PACKET_MIN || rb.Header.PacketLength > SMGP_PACKET_MAX {
-		return nil, ErrTotalLengthInvalid
-	}
-
-	if !((RequestID(rb.Header.RequestID) > SMGP_REQUEST_MIN && RequestID(rb.Header.RequestID) < SMGP_REQUEST_MAX) ||
-		(RequestID(rb.Header.RequestID) > SMGP_RESPONSE_MIN && RequestID(rb.Header.RequestID) < SMGP_RESPONSE_MAX)) {
-		return nil, ErrRequestIDInvalid
+	if err = checkPacketLength(&rb.Header, SMGP_PACKET_MAX); err != nil {
+		return nil, nil, err
 	}
 
 	if timeout != 0 {
@@ -176,51 +185,19 @@ func (c *Conn) RecvAndUnpackPkt(timeout time.Duration) (Packer, error) {
 			netErr, ok := err.(net.Error)
 			if ok {
 				if netErr.Timeout() {
-					return nil, ErrReadPktBodyTimeout
+					return nil, nil, ErrReadPktBodyTimeout
 				}
 			}
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
-	var p Packer
-	sequenceID := rb.Header.SequenceID
-	//fmt.Println("===============")
-	//fmt.Println(RequestID(rb.Header.RequestID))
-
-	switch RequestID(rb.Header.RequestID) {
-	case SMGP_ACTIVE_TEST:
-		p = &SmgpActiveTestReqPkt{SequenceID: sequenceID}
-	case SMGP_ACTIVE_TEST_RESP:
-		p = &SmgpActiveTestRespPkt{SequenceID: sequenceID}
-	case SMGP_LOGIN:
-		p = &SmgpLoginReqPkt{SequenceID: sequenceID}
-	case SMGP_LOGIN_RESP:
-		p = &SmgpLoginRespPkt{SequenceID: sequenceID}
-	case SMGP_SUBMIT:
-		p = &SmgpSubmitReqPkt{SequenceID: sequenceID}
-	case SMGP_SUBMIT_RESP:
-		p = &SmgpSubmitRespPkt{SequenceID: sequenceID}
-	case SMGP_DELIVER:
-		p = &SmgpDeliverReqPkt{SequenceID: sequenceID}
-	case SMGP_DELIVER_RESP:
-		p = &SmgpDeliverRespPkt{SequenceID: sequenceID}
-	case SMGP_EXIT:
-		p = &SmgpExitReqPkt{SequenceID: sequenceID}
-	case SMGP_EXIT_RESP:
-		p = &SmgpExitRespPkt{SequenceID: sequenceID}
-	case SMGP_QUERY:
-		p = &SmgpQueryReqPkt{SequenceID: sequenceID}
-	case SMGP_QUERY_RESP:
-		p = &SmgpQueryRespPkt{SequenceID: sequenceID}
-
-	default:
-		return nil, ErrRequestIDNotSupported
-	}
+	header := rb.Header
 
-	err = p.Unpack(leftData)
+	// 包体读出后再校验命令字，未知命令不会打乱后续读取
+	p, err := unpackFrame(header, leftData, c.ReturnRawPkt)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return p, nil
+	return &header, p, nil
 }
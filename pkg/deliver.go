@@ -3,7 +3,6 @@ package pkg
 import (
 	"bytes"
 	"encoding/binary"
-	"encoding/hex"
 	"fmt"
 )
 
@@ -11,54 +10,8 @@ const (
 	SmgpDeliverRespPktLen = HeaderPktLen + 10 + 4 //26d, 0x1a
 )
 
-type SmgpDeliverMsgContent struct {
-	SubmitMsgID string // submit resp 的 MsgID
-	Sub         string
-	Dlvrd       string
-	SubmitDate  string
-	DoneDate    string
-	Stat        string
-	Err         string
-	Txt         string
-}
-
-func (p *SmgpDeliverMsgContent) Encode() string {
-	id, _ := hex.DecodeString(p.SubmitMsgID)
-	msgStatStr := fmt.Sprintf("id:%s sub:%s dlvrd:%s submit_date:%s done_date:%s stat:%s err:%s text:%s", id, p.Sub, p.Dlvrd, p.SubmitDate, p.DoneDate, p.Stat, p.Err, p.Txt)
-
-	return msgStatStr
-}
-
-func DecodeDeliverMsgContent(data []byte) *SmgpDeliverMsgContent {
-	p := &SmgpDeliverMsgContent{}
-	p.SubmitMsgID = hex.EncodeToString(data[3:13])
-	p.Sub = string(data[18:21])
-	p.Dlvrd = string(data[28:31])
-	p.SubmitDate = string(data[44:54])
-	p.DoneDate = string(data[65:75])
-	p.Stat = string(data[81:88])
-	p.Err = string(data[93:96])
-	p.Txt = string(data[102:])
-	return p
-}
-
-func (p *SmgpDeliverMsgContent) String() string {
-	var b bytes.Buffer
-	fmt.Fprintln(&b, "")
-	fmt.Fprintln(&b, "\tID(SubmitMsgID): ", p.SubmitMsgID)
-	fmt.Fprintln(&b, "\tSub: ", p.Sub)
-	fmt.Fprintln(&b, "\tDlvrd: ", p.Dlvrd)
-	fmt.Fprintln(&b, "\tSubmitDate: ", p.SubmitDate)
-	fmt.Fprintln(&b, "\tDoneDate: ", p.DoneDate)
-	fmt.Fprintln(&b, "\tStat: ", p.Stat)
-	fmt.Fprintln(&b, "\tErr: ", p.Err)
-	fmt.Fprintln(&b, "\tTxt: ", p.Txt)
-
-	return b.String()
-}
-
 type SmgpDeliverReqPkt struct {
-	MsgID      string // 短消息流水号
+	MsgID      MsgID  // 短消息流水号
 	IsReport   uint8  // 是否为状态报告
 	MsgFormat  uint8  // 短消息格式
 	RecvTime   string // 短消息接收时间
@@ -70,12 +23,30 @@ type SmgpDeliverReqPkt struct {
 
 	// 可选字段
 	Options Options
+	// 解析时按原始顺序保留的全部可选参数，含重复的 Tag，仅供查看
+	OptionList []*TLV
 
 	// used in session
 	SequenceID     uint32
 	MsgStatContent *SmgpDeliverMsgContent
 }
 
+// 生成状态报告的 Deliver 请求，srcTermID 为原短消息的接收号码，destTermID 为原短消息的发送号码
+func NewReportDeliver(msgID MsgID, report *SmgpDeliverMsgContent, srcTermID, destTermID string) *SmgpDeliverReqPkt {
+	content := report.Bytes()
+	return &SmgpDeliverReqPkt{
+		MsgID:          msgID,
+		IsReport:       IS_REPORT,
+		MsgFormat:      ASCII,
+		RecvTime:       GenNowTimeYYYYStr(),
+		SrcTermID:      srcTermID,
+		DestTermID:     destTermID,
+		MsgLength:      uint8(len(content)),
+		MsgContent:     content,
+		MsgStatContent: report,
+	}
+}
+
 func (p *SmgpDeliverReqPkt) Pack(seqId uint32) ([]byte, error) {
 	var pktLen = HeaderPktLen + 77 + uint32(p.MsgLength) + uint32(p.Options.Len())
 	var w = newPkgWriter(pktLen)
@@ -84,8 +55,7 @@ func (p *SmgpDeliverReqPkt) Pack(seqId uint32) ([]byte, error) {
 	p.SequenceID = seqId
 
 	// body
-	msgId, _ := hex.DecodeString(p.MsgID)
-	w.WriteBytes(NewOctetString(fmt.Sprintf("%s", msgId)).Byte(10))
+	w.WriteBytes(p.MsgID[:])
 	w.WriteByte(p.IsReport)
 	w.WriteByte(p.MsgFormat)
 	w.WriteFixedSizeString(p.RecvTime, 14)
@@ -95,10 +65,7 @@ func (p *SmgpDeliverReqPkt) Pack(seqId uint32) ([]byte, error) {
 	w.WriteBytes(p.MsgContent)
 	w.WriteFixedSizeString(p.Reserve, 8)
 
-	for _, o := range p.Options {
-		b, _ := o.Byte()
-		w.WriteBytes(b)
-	}
+	p.Options.Pack(w)
 
 	return w.Bytes()
 }
@@ -107,7 +74,7 @@ func (p *SmgpDeliverReqPkt) Unpack(data []byte) error {
 	var r = newPkgReader(data)
 	offset := 0
 
-	p.MsgID = hex.EncodeToString(r.ReadCString(10))
+	r.ReadBytes(p.MsgID[:])
 	p.IsReport = r.ReadByte()
 	p.MsgFormat = r.ReadByte()
 	p.RecvTime = string(r.ReadCString(14))
@@ -118,21 +85,38 @@ func (p *SmgpDeliverReqPkt) Unpack(data []byte) error {
 	s := make([]byte, p.MsgLength)
 	r.ReadBytes(s)
 	p.MsgContent = s
-	// 状态报告
-	if p.IsReport == 1 {
-		p.MsgStatContent = DecodeDeliverMsgContent(p.MsgContent)
-	}
-
 	p.Reserve = string(r.ReadCString(8))
 	offset += 10 + 1 + 1 + 14 + 21 + 21 + 1 + int(p.MsgLength) + 8
 
-	options, err := ParseOptions(data[offset:])
+	if err := r.Error(); err != nil {
+		return err
+	}
+
+	// 状态报告，内容格式不正确时不影响整个包的解析，可通过 Report() 取得错误
+	p.MsgStatContent = nil
+	if p.IsReport == IS_REPORT {
+		p.MsgStatContent, _ = DecodeDeliverMsgContent(p.MsgContent)
+	}
+
+	optionList, err := ParseOptionList(data[offset:])
 	if err != nil {
 		return err
 	}
-	p.Options = options
+	p.OptionList = optionList
+	p.Options = NewOptions(optionList...)
 
-	return r.Error()
+	return nil
+}
+
+// 解析状态报告内容，非状态报告时返回错误
+func (p *SmgpDeliverReqPkt) Report() (*SmgpDeliverMsgContent, error) {
+	if p.IsReport != IS_REPORT {
+		return nil, NewOpError(ErrReportInvalid, "Report: not a status report")
+	}
+	if p.MsgStatContent != nil {
+		return p.MsgStatContent, nil
+	}
+	return DecodeDeliverMsgContent(p.MsgContent)
 }
 
 func (p *SmgpDeliverReqPkt) String() string {
@@ -145,7 +129,7 @@ func (p *SmgpDeliverReqPkt) String() string {
 	fmt.Fprintln(&b, "SrcTermID: ", p.SrcTermID)
 	fmt.Fprintln(&b, "DestTermID: ", p.DestTermID)
 	fmt.Fprintln(&b, "MsgLength: ", p.MsgLength)
-	if p.IsReport == 1 {
+	if p.IsReport == IS_REPORT && p.MsgStatContent != nil {
 		fmt.Fprintln(&b, "MsgContent: ", p.MsgStatContent.String())
 	} else {
 		fmt.Fprintln(&b, "MsgContent: ", string(p.MsgContent))
@@ -155,8 +139,14 @@ func (p *SmgpDeliverReqPkt) String() string {
 	return b.String()
 }
 
+func (p *SmgpDeliverReqPkt) PrepareResponse(rsp Packer) {
+	if r, ok := rsp.(*SmgpDeliverRespPkt); ok {
+		r.MsgID = p.MsgID
+	}
+}
+
 type SmgpDeliverRespPkt struct {
-	MsgID  string
+	MsgID  MsgID
 	Status Status
 
 	// used in session
@@ -170,8 +160,7 @@ func (p *SmgpDeliverRespPkt) Pack(seqId uint32) ([]byte, error) {
 	p.SequenceID = seqId
 
 	// body
-	msgId, _ := hex.DecodeString(p.MsgID)
-	w.WriteBytes(NewOctetString(fmt.Sprintf("%s", msgId)).Byte(10))
+	w.WriteBytes(p.MsgID[:])
 	w.WriteInt(binary.BigEndian, p.Status)
 
 	return w.Bytes()
@@ -181,9 +170,7 @@ func (p *SmgpDeliverRespPkt) Unpack(data []byte) error {
 	var r = newPkgReader(data)
 
 	// Body: MsgID
-	var s = make([]byte, 10)
-	r.ReadBytes(s)
-	p.MsgID = hex.EncodeToString(s)
+	r.ReadBytes(p.MsgID[:])
 	// Body: Status
 	r.ReadInt(binary.BigEndian, &p.Status)
 
@@ -0,0 +1,135 @@
+package pkg
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+var ErrNotASCII = errors.New("text contains non-ASCII characters")
+
+// 短消息编码选择策略
+type EncodePolicy uint8
+
+const (
+	// 纯 ASCII 文本用 ASCII；否则单条能放下时用 GB18030，需要分段时用 UCS2，
+	// 部分终端只能正确合并 UCS2 编码的长短信
+	ENCODE_AUTO EncodePolicy = iota
+	ENCODE_ASCII
+	ENCODE_GB18030
+	ENCODE_UCS2
+)
+
+// 编码并切分后的短消息
+type EncodedText struct {
+	MsgFormat uint8
+	// 每段内容，多于一段或带端口等信息元素时已带 UDH
+	Segments [][]byte
+
+	udhi bool
+}
+
+// 内容带 UDH 时需设置 TP_udhi=1
+func (e *EncodedText) TPUdhi() uint8 {
+	if e.udhi || len(e.Segments) > 1 {
+		return 1
+	}
+	return 0
+}
+
+// 以 tmpl 为模板生成每段的 Submit，填入 MsgFormat、MsgLength、MsgContent，
+// 以及 TP_udhi、PkTotal、PkNumber 可选参数，模板中的其它可选参数原样保留
+func (e *EncodedText) Submits(tmpl *SmgpSubmitReqPkt) []*SmgpSubmitReqPkt {
+	packets := make([]*SmgpSubmitReqPkt, 0, len(e.Segments))
+	for i, seg := range e.Segments {
+		p := *tmpl
+		p.MsgFormat = e.MsgFormat
+		p.MsgLength = uint8(len(seg))
+		p.MsgContent = seg
+		p.OptionList = nil
+		p.SequenceID = 0
+
+		p.Options = make(Options, len(tmpl.Options)+3)
+		for tag, tlv := range tmpl.Options {
+			p.Options[tag] = tlv
+		}
+		p.Options.SetTPUdhi(e.TPUdhi())
+		p.Options.SetPkTotal(uint8(len(e.Segments)))
+		p.Options.SetPkNumber(uint8(i + 1))
+
+		packets = append(packets, &p)
+	}
+	return packets
+}
+
+// 按 policy 选择编码并切分 text，参考号取自包内默认分配器
+func EncodeText(text string, policy EncodePolicy) (*EncodedText, error) {
+	return EncodeTextWithRefs(text, policy, nil)
+}
+
+// 同 EncodeText，参考号取自 refs，通常为 Conn.ConcatRefs
+func EncodeTextWithRefs(text string, policy EncodePolicy, refs *RefAllocator) (*EncodedText, error) {
+	format, content, err := encodeText(text, policy)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := NewSplitter(format, refs).Split(content)
+	if err != nil {
+		return nil, err
+	}
+	return &EncodedText{MsgFormat: format, Segments: segments}, nil
+}
+
+// 按 ENCODE_AUTO 估算 text 需要的短消息条数，不占用参考号。
+// 无法编码或超过 MaxSegmentsCount 段时返回与 EncodeText 相同的错误
+func EstimateSegments(text string) (int, error) {
+	format, content, err := encodeText(text, ENCODE_AUTO)
+	if err != nil {
+		return 0, err
+	}
+	return NewSplitter(format, nil).Count(content)
+}
+
+func encodeText(text string, policy EncodePolicy) (uint8, []byte, error) {
+	if !utf8.ValidString(text) {
+		return 0, nil, errors.New("invalid utf8 runes")
+	}
+
+	switch policy {
+	case ENCODE_AUTO:
+		if isASCII(text) {
+			return ASCII, []byte(text), nil
+		}
+		gb, err := Utf8ToGB18030(text)
+		if err != nil {
+			return 0, nil, err
+		}
+		if len(gb) <= MaxSmsLength {
+			return GB18030, []byte(gb), nil
+		}
+		return encodeText(text, ENCODE_UCS2)
+
+	case ENCODE_ASCII:
+		if !isASCII(text) {
+			return 0, nil, ErrNotASCII
+		}
+		return ASCII, []byte(text), nil
+
+	case ENCODE_GB18030:
+		gb, err := Utf8ToGB18030(text)
+		return GB18030, []byte(gb), err
+
+	case ENCODE_UCS2:
+		u, err := Utf8ToUcs2(text)
+		return UCS2, []byte(u), err
+	}
+	return 0, nil, ErrMethodParamsInvalid
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,166 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/boxtsecond/gosmgp/pkg/udh"
+)
+
+// 去掉各段 UDH 后拼接并按 format 解码
+func decodeSegments(t *testing.T, e *EncodedText) string {
+	t.Helper()
+	var content []byte
+	for _, seg := range e.Segments {
+		if e.TPUdhi() == 1 {
+			_, body, err := udh.Parse(seg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			seg = body
+		}
+		content = append(content, seg...)
+	}
+
+	var s string
+	var err error
+	switch e.MsgFormat {
+	case ASCII:
+		s = string(content)
+	case GB18030:
+		s, err = GB18030ToUtf8(string(content))
+	case UCS2:
+		s, err = Ucs2ToUtf8(string(content))
+	default:
+		t.Fatalf("unexpected MsgFormat %d", e.MsgFormat)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestEncodeTextAuto(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		format   uint8
+		segments int
+	}{
+		{"ascii", "hello", ASCII, 1},
+		{"ascii long", strings.Repeat("a", 141), ASCII, 2},
+		{"chinese", "你好", GB18030, 1},
+		// GB18030 每个汉字2字节，70个正好140字节
+		{"chinese 140 bytes", strings.Repeat("中", 70), GB18030, 1},
+		// 超过一条时改用 UCS2，每段67个汉字
+		{"chinese 142 bytes", strings.Repeat("中", 71), UCS2, 2},
+		{"chinese 3 segments", strings.Repeat("中", 135), UCS2, 3},
+		// 表情符号在 GB18030 中为4字节，68个汉字加1个表情正好140字节
+		{"emoji 140 bytes", strings.Repeat("中", 68) + "😀", GB18030, 1},
+		{"emoji 142 bytes", strings.Repeat("中", 69) + "😀", UCS2, 2},
+		{"mixed", "ok 好的", GB18030, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := EncodeTextWithRefs(tt.text, ENCODE_AUTO, NewRefAllocator())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.MsgFormat != tt.format || len(e.Segments) != tt.segments {
+				t.Fatalf("MsgFormat %d with %d segments, want %d with %d",
+					e.MsgFormat, len(e.Segments), tt.format, tt.segments)
+			}
+			if got := decodeSegments(t, e); got != tt.text {
+				t.Errorf("decoded %q, want %q", got, tt.text)
+			}
+			if n, err := EstimateSegments(tt.text); err != nil || n != tt.segments {
+				t.Errorf("EstimateSegments = %d, %v, want %d", n, err, tt.segments)
+			}
+		})
+	}
+}
+
+func TestEncodeTextPolicies(t *testing.T) {
+	long := strings.Repeat("中", 71)
+	tests := []struct {
+		policy   EncodePolicy
+		text     string
+		format   uint8
+		segments int
+		err      error
+	}{
+		{ENCODE_ASCII, "hello", ASCII, 1, nil},
+		{ENCODE_ASCII, "你好", 0, 0, ErrNotASCII},
+		{ENCODE_GB18030, "hello", GB18030, 1, nil},
+		{ENCODE_GB18030, long, GB18030, 2, nil},
+		{ENCODE_UCS2, "你好", UCS2, 1, nil},
+		{ENCODE_UCS2, long, UCS2, 2, nil},
+		{EncodePolicy(9), "hello", 0, 0, ErrMethodParamsInvalid},
+	}
+	for _, tt := range tests {
+		e, err := EncodeText(tt.text, tt.policy)
+		if err != tt.err {
+			t.Errorf("policy %d: error = %v, want %v", tt.policy, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if e.MsgFormat != tt.format || len(e.Segments) != tt.segments {
+			t.Errorf("policy %d: MsgFormat %d with %d segments, want %d with %d",
+				tt.policy, e.MsgFormat, len(e.Segments), tt.format, tt.segments)
+		}
+		if got := decodeSegments(t, e); got != tt.text {
+			t.Errorf("policy %d: decoded %q, want %q", tt.policy, got, tt.text)
+		}
+	}
+}
+
+func TestEstimateSegmentsErrors(t *testing.T) {
+	if n, err := EstimateSegments("\xff"); err == nil || n != 0 {
+		t.Errorf("EstimateSegments(invalid utf8) = %d, %v, want an error", n, err)
+	}
+
+	// UCS2 每段134字节即67个汉字，255段之外再多一个字
+	text := strings.Repeat("中", 67*MaxSegmentsCount+1)
+	if n, err := EstimateSegments(text); err != ErrTooManySegments || n != 0 {
+		t.Errorf("EstimateSegments(%d runes) = %d, %v, want %v", 67*MaxSegmentsCount+1, n, err, ErrTooManySegments)
+	}
+	if _, err := EncodeText(text, ENCODE_AUTO); err != ErrTooManySegments {
+		t.Errorf("EncodeText error = %v, want %v", err, ErrTooManySegments)
+	}
+	if n, err := EstimateSegments(strings.Repeat("中", 67*MaxSegmentsCount)); err != nil || n != MaxSegmentsCount {
+		t.Errorf("EstimateSegments(%d runes) = %d, %v, want %d", 67*MaxSegmentsCount, n, err, MaxSegmentsCount)
+	}
+}
+
+func TestEncodedTextSubmits(t *testing.T) {
+	tmpl := &SmgpSubmitReqPkt{SrcTermID: "10661", DestTermIDCount: 1, DestTermID: []string{"8618000000001"}}
+	tmpl.Options.SetLinkID("link")
+
+	for _, text := range []string{"hello", strings.Repeat("中", 71)} {
+		e, err := EncodeText(text, ENCODE_AUTO)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ps := e.Submits(tmpl)
+		if len(ps) != len(e.Segments) {
+			t.Fatalf("%d submits for %d segments", len(ps), len(e.Segments))
+		}
+		for i, p := range ps {
+			udhi, _ := p.Options.TPUdhi()
+			total, _ := p.Options.PkTotal()
+			number, _ := p.Options.PkNumber()
+			linkID, _ := p.Options.LinkID()
+			if udhi != e.TPUdhi() || int(total) != len(ps) || int(number) != i+1 || linkID != "link" {
+				t.Errorf("submit %d options: udhi %d, %d/%d, LinkID %q", i+1, udhi, number, total, linkID)
+			}
+			if p.MsgFormat != e.MsgFormat || int(p.MsgLength) != len(e.Segments[i]) || p.SrcTermID != "10661" {
+				t.Errorf("submit %d = %+v", i+1, p)
+			}
+		}
+	}
+	if _, ok := tmpl.Options[TAG_PkTotal]; ok {
+		t.Error("template options modified")
+	}
+}
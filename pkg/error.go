@@ -10,6 +10,7 @@ var (
 	ErrTotalLengthInvalid    = errors.New("PacketLength in Packet data is invalid")
 	ErrRequestIDInvalid      = errors.New("RequestID in Packet data is invalid")
 	ErrRequestIDNotSupported = errors.New("RequestID in Packet data is not supported")
+	ErrIncompleteFrame       = errors.New("Packet data is not a complete frame")
 
 	// Connection errors.
 	ErrConnIsClosed       = errors.New("connection is closed")
@@ -23,7 +23,7 @@ func (p *SmgpExitReqPkt) Pack(seqId uint32) ([]byte, error) {
 	var w = newPkgWriter(SmgpExitReqPktLen)
 
 	// header
-	w.WriteHeader(SmgpExitReqPktLen, seqId, SMGP_ACTIVE_TEST)
+	w.WriteHeader(SmgpExitReqPktLen, seqId, SMGP_EXIT)
 	p.SequenceID = seqId
 
 	return w.Bytes()
@@ -43,7 +43,7 @@ func (p *SmgpExitRespPkt) Pack(seqId uint32) ([]byte, error) {
 	var w = newPkgWriter(SmgpExitRespPktLen)
 
 	// header
-	w.WriteHeader(SmgpExitRespPktLen, seqId, SMGP_ACTIVE_TEST_RESP)
+	w.WriteHeader(SmgpExitRespPktLen, seqId, SMGP_EXIT_RESP)
 	p.SequenceID = seqId
 
 	return w.Bytes()
@@ -0,0 +1,195 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	SmgpForwardRespPktLen = HeaderPktLen + 10 + 4 //26d, 0x1a
+)
+
+// 网关之间转发短消息(Forward)
+type SmgpForwardReqPkt struct {
+	MsgID        MsgID    // 短消息流水号
+	DestSMGWNo   string   // 目的网关代码
+	SrcSMGWNo    string   // 源网关代码
+	SmcNo        string   // 短消息中心代码
+	MsgType      uint8    // 短消息类型
+	ReportFlag   uint8    // 是否要求返回状态报告
+	Priority     uint8    // 短消息发送优先级
+	ServiceID    string   // 业务代码
+	FeeType      string   // 收费类型
+	FeeCode      string   // 资费代码
+	FixedFee     string   // 包月费/封顶费
+	MsgFormat    uint8    // 短消息格式
+	ValidTime    SmgpTime // 短消息有效时间
+	AtTime       SmgpTime // 短消息定时发送时间
+	SrcTermID    string   // 短信息发送方号码
+	DestTermID   string   // 短消息接收号码
+	ChargeTermID string   // 计费用户号码
+	MsgLength    uint8    // 短消息长度
+	MsgContent   []byte   // 短消息内容
+	Reserve      string   // 保留
+
+	// 可选参数
+	Options Options
+	// 解析时按原始顺序保留的全部可选参数，含重复的 Tag，仅供查看
+	OptionList []*TLV
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpForwardReqPkt) Pack(seqId uint32) ([]byte, error) {
+	var pktLen = HeaderPktLen + 162 + uint32(p.MsgLength) + uint32(p.Options.Len())
+	var w = newPkgWriter(pktLen)
+	// header
+	w.WriteHeader(pktLen, seqId, SMGP_FORWARD)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteBytes(p.MsgID[:])
+	w.WriteFixedSizeString(p.DestSMGWNo, 6)
+	w.WriteFixedSizeString(p.SrcSMGWNo, 6)
+	w.WriteFixedSizeString(p.SmcNo, 6)
+	w.WriteByte(p.MsgType)
+	w.WriteByte(p.ReportFlag)
+	w.WriteByte(p.Priority)
+	w.WriteFixedSizeString(p.ServiceID, 10)
+	w.WriteFixedSizeString(p.FeeType, 2)
+	w.WriteFixedSizeString(p.FeeCode, 6)
+	w.WriteFixedSizeString(p.FixedFee, 6)
+	w.WriteByte(p.MsgFormat)
+	w.WriteFixedSizeString(string(p.ValidTime), 17)
+	w.WriteFixedSizeString(string(p.AtTime), 17)
+	w.WriteFixedSizeString(p.SrcTermID, 21)
+	w.WriteFixedSizeString(p.DestTermID, 21)
+	w.WriteFixedSizeString(p.ChargeTermID, 21)
+	w.WriteByte(p.MsgLength)
+	w.WriteBytes(p.MsgContent)
+	w.WriteFixedSizeString(p.Reserve, 8)
+
+	p.Options.Pack(w)
+
+	return w.Bytes()
+}
+
+func (p *SmgpForwardReqPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+	offset := 0
+
+	r.ReadBytes(p.MsgID[:])
+	p.DestSMGWNo = string(r.ReadCString(6))
+	p.SrcSMGWNo = string(r.ReadCString(6))
+	p.SmcNo = string(r.ReadCString(6))
+	p.MsgType = r.ReadByte()
+	p.ReportFlag = r.ReadByte()
+	p.Priority = r.ReadByte()
+	p.ServiceID = string(r.ReadCString(10))
+	p.FeeType = string(r.ReadCString(2))
+	p.FeeCode = string(r.ReadCString(6))
+	p.FixedFee = string(r.ReadCString(6))
+	p.MsgFormat = r.ReadByte()
+	p.ValidTime = SmgpTime(r.ReadCString(17))
+	p.AtTime = SmgpTime(r.ReadCString(17))
+	p.SrcTermID = string(r.ReadCString(21))
+	p.DestTermID = string(r.ReadCString(21))
+	p.ChargeTermID = string(r.ReadCString(21))
+	p.MsgLength = r.ReadByte()
+	msgContent := make([]byte, p.MsgLength)
+	r.ReadBytes(msgContent)
+	p.MsgContent = msgContent
+	p.Reserve = string(r.ReadCString(8))
+	offset += 162 + int(p.MsgLength)
+
+	if err := r.Error(); err != nil {
+		return err
+	}
+
+	optionList, err := ParseOptionList(data[offset:])
+	if err != nil {
+		return err
+	}
+	p.OptionList = optionList
+	p.Options = NewOptions(optionList...)
+
+	return nil
+}
+
+func (p *SmgpForwardReqPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Forward Req ---")
+	fmt.Fprintln(&b, "MsgID: ", p.MsgID)
+	fmt.Fprintln(&b, "DestSMGWNo: ", p.DestSMGWNo)
+	fmt.Fprintln(&b, "SrcSMGWNo: ", p.SrcSMGWNo)
+	fmt.Fprintln(&b, "SmcNo: ", p.SmcNo)
+	fmt.Fprintln(&b, "MsgType: ", p.MsgType)
+	fmt.Fprintln(&b, "ReportFlag: ", p.ReportFlag)
+	fmt.Fprintln(&b, "Priority: ", p.Priority)
+
+	fmt.Fprintln(&b, "ServiceID: ", p.ServiceID)
+	fmt.Fprintln(&b, "FeeType: ", p.FeeType)
+	fmt.Fprintln(&b, "FeeCode: ", p.FeeCode)
+	fmt.Fprintln(&b, "FixedFee: ", p.FixedFee)
+
+	fmt.Fprintln(&b, "MsgFormat: ", p.MsgFormat)
+	fmt.Fprintln(&b, "ValidTime: ", p.ValidTime)
+	fmt.Fprintln(&b, "AtTime: ", p.AtTime)
+	fmt.Fprintln(&b, "SrcTermID: ", p.SrcTermID)
+	fmt.Fprintln(&b, "DestTermID: ", p.DestTermID)
+	fmt.Fprintln(&b, "ChargeTermID: ", p.ChargeTermID)
+
+	fmt.Fprintln(&b, "MsgLength: ", p.MsgLength)
+	fmt.Fprintln(&b, "MsgContent: ", string(p.MsgContent))
+	fmt.Fprintln(&b, "Options: ", p.Options.String())
+
+	return b.String()
+}
+
+func (p *SmgpForwardReqPkt) PrepareResponse(rsp Packer) {
+	if r, ok := rsp.(*SmgpForwardRespPkt); ok {
+		r.MsgID = p.MsgID
+	}
+}
+
+type SmgpForwardRespPkt struct {
+	MsgID  MsgID
+	Status Status
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpForwardRespPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpForwardRespPktLen)
+	// header
+	w.WriteHeader(SmgpForwardRespPktLen, seqId, SMGP_FORWARD_RESP)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteBytes(p.MsgID[:])
+	w.WriteInt(binary.BigEndian, p.Status)
+
+	return w.Bytes()
+}
+
+func (p *SmgpForwardRespPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	// Body: MsgID
+	r.ReadBytes(p.MsgID[:])
+	// Body: Status
+	r.ReadInt(binary.BigEndian, &p.Status)
+
+	return r.Error()
+}
+
+func (p *SmgpForwardRespPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Forward Resp ---")
+	fmt.Fprintln(&b, "MsgID: ", p.MsgID)
+	fmt.Fprintln(&b, "Status: ", p.Status)
+	return b.String()
+}
@@ -33,7 +33,7 @@ func (p *Header) String() string {
 	var b bytes.Buffer
 	fmt.Fprintln(&b, "--- Header ---")
 	fmt.Fprintln(&b, "Length: ", p.PacketLength)
-	fmt.Fprintf(&b, "RequestID: 0x%x\n", p.RequestID)
+	fmt.Fprintf(&b, "RequestID: 0x%x(%s)\n", p.RequestID, RequestID(p.RequestID))
 	fmt.Fprintln(&b, "SequenceID: ", p.SequenceID)
 
 	return b.String()
@@ -0,0 +1,115 @@
+package pkg
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrMsgIDInvalid = errors.New("MsgID: invalid msg id")
+
+// 短消息流水号，10字节BCD码：
+// SMGW代码3字节 + 时间4字节(MMDDHHMM) + 序列号3字节(000000～999999)。
+// 文本形式为20位十六进制字符串，对BCD码而言即为其十进制数字，
+// 如 01006101161700012345。
+type MsgID [10]byte
+
+// 由网关代码、接收时间与序列号生成 MsgID，smgwCode 不足6位时左补0
+func NewMsgID(smgwCode string, t time.Time, seq uint32) (MsgID, error) {
+	var id MsgID
+	if len(smgwCode) > 6 || !isDigits(smgwCode) {
+		return id, NewOpError(ErrMsgIDInvalid, "NewMsgID: smgw code "+smgwCode)
+	}
+	if seq > 999999 {
+		return id, NewOpError(ErrMsgIDInvalid, fmt.Sprintf("NewMsgID: sequence %d", seq))
+	}
+
+	digits := fmt.Sprintf("%06s%02d%02d%02d%02d%06d",
+		smgwCode, int(t.Month()), t.Day(), t.Hour(), t.Minute(), seq)
+	_, err := hex.Decode(id[:], []byte(digits))
+	return id, err
+}
+
+// 解析20位十六进制字符串形式的 MsgID
+func ParseMsgID(s string) (MsgID, error) {
+	var id MsgID
+	if len(s) != 2*len(id) {
+		return id, NewOpError(ErrMsgIDInvalid, "ParseMsgID: "+s)
+	}
+	if _, err := hex.Decode(id[:], []byte(s)); err != nil {
+		return id, NewOpError(ErrMsgIDInvalid, "ParseMsgID: "+s)
+	}
+	return id, nil
+}
+
+// 由10字节原始数据生成 MsgID，不足补0，超出截断
+func MsgIDFromBytes(b []byte) MsgID {
+	var id MsgID
+	copy(id[:], b)
+	return id
+}
+
+func (m MsgID) IsZero() bool {
+	return m == MsgID{}
+}
+
+// 是否每个半字节都是合法的BCD码
+func (m MsgID) IsBCD() bool {
+	for _, b := range m {
+		if b>>4 > 9 || b&0x0f > 9 {
+			return false
+		}
+	}
+	return true
+}
+
+func (m MsgID) String() string {
+	return hex.EncodeToString(m[:])
+}
+
+// SMGW代码，如 010061
+func (m MsgID) GatewayCode() string {
+	return hex.EncodeToString(m[0:3])
+}
+
+// 接收时间，格式为 MMDDHHMM
+func (m MsgID) Timestamp() string {
+	return hex.EncodeToString(m[3:7])
+}
+
+// 接收时间，MsgID 中不含年份与时区，由调用方指定
+func (m MsgID) Time(year int, loc *time.Location) (time.Time, error) {
+	ts := m.Timestamp()
+	t, err := time.ParseInLocation("0102150405", ts+"00", loc)
+	if err != nil {
+		return time.Time{}, NewOpError(ErrMsgIDInvalid, "MsgID.Time: "+ts)
+	}
+	return t.AddDate(year-t.Year(), 0, 0), nil
+}
+
+// 序列号，非BCD码时返回错误
+func (m MsgID) Sequence() (uint32, error) {
+	var seq uint32
+	for _, b := range m[7:10] {
+		hi, lo := b>>4, b&0x0f
+		if hi > 9 || lo > 9 {
+			return 0, NewOpError(ErrMsgIDInvalid, "MsgID.Sequence: "+m.String())
+		}
+		seq = seq*100 + uint32(hi)*10 + uint32(lo)
+	}
+	return seq, nil
+}
+
+func (m MsgID) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *MsgID) UnmarshalText(text []byte) error {
+	id, err := ParseMsgID(string(text))
+	if err != nil {
+		return err
+	}
+	*m = id
+	return nil
+}
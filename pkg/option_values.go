@@ -0,0 +1,216 @@
+package pkg
+
+import "bytes"
+
+// Options 各可选参数的类型化读写。
+// Get 方法在参数不存在时返回 ErrOptionNotFound，长度不符时返回 ErrLength；
+// Set 方法会在 Options 为 nil 时自动创建。
+
+func (o Options) uint8Value(tag Tag) (uint8, error) {
+	t, ok := o[tag]
+	if !ok {
+		return 0, NewOpError(ErrOptionNotFound, "Options "+tag.String())
+	}
+	if err := checkTagLength(tag, len(t.Value)); err != nil {
+		return 0, err
+	}
+	return t.Value[0], nil
+}
+
+func (o Options) stringValue(tag Tag) (string, error) {
+	t, ok := o[tag]
+	if !ok {
+		return "", NewOpError(ErrOptionNotFound, "Options "+tag.String())
+	}
+	if err := checkTagLength(tag, len(t.Value)); err != nil {
+		return "", err
+	}
+	// 去除右补的0x00
+	return string(bytes.TrimRight(t.Value, "\x00")), nil
+}
+
+func (o *Options) set(tag Tag, value []byte) {
+	if *o == nil {
+		*o = make(Options)
+	}
+	(*o)[tag] = NewTLV(tag, value)
+}
+
+func (o *Options) setUint8(tag Tag, v uint8) {
+	o.set(tag, []byte{v})
+}
+
+func (o *Options) setString(tag Tag, s string) error {
+	sz := tagSizes[tag]
+	if len(s) > sz.size {
+		return checkTagLength(tag, len(s))
+	}
+	value := []byte(s)
+	if sz.fixed {
+		value = NewOctetString(s).Byte(sz.size)
+	}
+	o.set(tag, value)
+	return nil
+}
+
+// GSM协议类型
+func (o Options) TPPid() (uint8, error) {
+	return o.uint8Value(TAG_TP_pid)
+}
+
+func (o *Options) SetTPPid(v uint8) {
+	o.setUint8(TAG_TP_pid, v)
+}
+
+// GSM协议类型，表示是否包含消息头
+func (o Options) TPUdhi() (uint8, error) {
+	return o.uint8Value(TAG_TP_udhi)
+}
+
+func (o *Options) SetTPUdhi(v uint8) {
+	o.setUint8(TAG_TP_udhi, v)
+}
+
+// 计费用户类型
+func (o Options) ChargeUserType() (uint8, error) {
+	return o.uint8Value(TAG_ChargeUserType)
+}
+
+func (o *Options) SetChargeUserType(v uint8) {
+	o.setUint8(TAG_ChargeUserType, v)
+}
+
+// 计费用户的号码类型
+func (o Options) ChargeTermType() (uint8, error) {
+	return o.uint8Value(TAG_ChargeTermType)
+}
+
+func (o *Options) SetChargeTermType(v uint8) {
+	o.setUint8(TAG_ChargeTermType, v)
+}
+
+// 短消息接收方号码类型
+func (o Options) DestTermType() (uint8, error) {
+	return o.uint8Value(TAG_DestTermType)
+}
+
+func (o *Options) SetDestTermType(v uint8) {
+	o.setUint8(TAG_DestTermType, v)
+}
+
+// 相同MsgID的消息总条数
+func (o Options) PkTotal() (uint8, error) {
+	return o.uint8Value(TAG_PkTotal)
+}
+
+func (o *Options) SetPkTotal(v uint8) {
+	o.setUint8(TAG_PkTotal, v)
+}
+
+// 相同MsgID的消息序号
+func (o Options) PkNumber() (uint8, error) {
+	return o.uint8Value(TAG_PkNumber)
+}
+
+func (o *Options) SetPkNumber(v uint8) {
+	o.setUint8(TAG_PkNumber, v)
+}
+
+// SP发送的消息类型
+func (o Options) SubmitMsgType() (uint8, error) {
+	return o.uint8Value(TAG_SubmitMsgType)
+}
+
+func (o *Options) SetSubmitMsgType(v uint8) {
+	o.setUint8(TAG_SubmitMsgType, v)
+}
+
+// SP对消息的处理结果
+func (o Options) SPDealResult() (uint8, error) {
+	return o.uint8Value(TAG_SPDealResult)
+}
+
+func (o *Options) SetSPDealResult(v uint8) {
+	o.setUint8(TAG_SPDealResult, v)
+}
+
+// 短消息发送方号码类型
+func (o Options) SrcTermType() (uint8, error) {
+	return o.uint8Value(TAG_SrcTermType)
+}
+
+func (o *Options) SetSrcTermType(v uint8) {
+	o.setUint8(TAG_SrcTermType, v)
+}
+
+// 经过的网关数量
+func (o Options) NodesCount() (uint8, error) {
+	return o.uint8Value(TAG_NodesCount)
+}
+
+func (o *Options) SetNodesCount(v uint8) {
+	o.setUint8(TAG_NodesCount, v)
+}
+
+// MsgSrc的类型
+func (o Options) SrcType() (uint8, error) {
+	return o.uint8Value(TAG_SrcType)
+}
+
+func (o *Options) SetSrcType(v uint8) {
+	o.setUint8(TAG_SrcType, v)
+}
+
+// 交易标识
+func (o Options) LinkID() (string, error) {
+	return o.stringValue(TAG_LinkID)
+}
+
+func (o *Options) SetLinkID(s string) error {
+	return o.setString(TAG_LinkID, s)
+}
+
+// 计费用户的伪码
+func (o Options) ChargeTermPseudo() (string, error) {
+	return o.stringValue(TAG_ChargeTermPseudo)
+}
+
+func (o *Options) SetChargeTermPseudo(s string) error {
+	return o.setString(TAG_ChargeTermPseudo, s)
+}
+
+// 短消息接收方伪码
+func (o Options) DestTermPseudo() (string, error) {
+	return o.stringValue(TAG_DestTermPseudo)
+}
+
+func (o *Options) SetDestTermPseudo(s string) error {
+	return o.setString(TAG_DestTermPseudo, s)
+}
+
+// 短消息发送方伪码
+func (o Options) SrcTermPseudo() (string, error) {
+	return o.stringValue(TAG_SrcTermPseudo)
+}
+
+func (o *Options) SetSrcTermPseudo(s string) error {
+	return o.setString(TAG_SrcTermPseudo, s)
+}
+
+// 信息内容的来源
+func (o Options) MsgSrc() (string, error) {
+	return o.stringValue(TAG_MsgSrc)
+}
+
+func (o *Options) SetMsgSrc(s string) error {
+	return o.setString(TAG_MsgSrc, s)
+}
+
+// 业务代码（用于WAP等业务）
+func (o Options) MServiceID() (string, error) {
+	return o.stringValue(TAG_MServiceID)
+}
+
+func (o *Options) SetMServiceID(s string) error {
+	return o.setString(TAG_MServiceID, s)
+}
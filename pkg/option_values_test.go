@@ -0,0 +1,132 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+// 取 OpError 包装的原始错误
+func errCause(err error) error {
+	if oe, ok := err.(*OpError); ok {
+		return oe.Cause()
+	}
+	return err
+}
+
+func TestUint8Options(t *testing.T) {
+	tests := []struct {
+		tag Tag
+		get func(Options) (uint8, error)
+		set func(*Options, uint8)
+	}{
+		{TAG_TP_pid, Options.TPPid, (*Options).SetTPPid},
+		{TAG_TP_udhi, Options.TPUdhi, (*Options).SetTPUdhi},
+		{TAG_ChargeUserType, Options.ChargeUserType, (*Options).SetChargeUserType},
+		{TAG_ChargeTermType, Options.ChargeTermType, (*Options).SetChargeTermType},
+		{TAG_DestTermType, Options.DestTermType, (*Options).SetDestTermType},
+		{TAG_PkTotal, Options.PkTotal, (*Options).SetPkTotal},
+		{TAG_PkNumber, Options.PkNumber, (*Options).SetPkNumber},
+		{TAG_SubmitMsgType, Options.SubmitMsgType, (*Options).SetSubmitMsgType},
+		{TAG_SPDealResult, Options.SPDealResult, (*Options).SetSPDealResult},
+		{TAG_SrcTermType, Options.SrcTermType, (*Options).SetSrcTermType},
+		{TAG_NodesCount, Options.NodesCount, (*Options).SetNodesCount},
+		{TAG_SrcType, Options.SrcType, (*Options).SetSrcType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag.String(), func(t *testing.T) {
+			var o Options
+			if _, err := tt.get(o); errCause(err) != ErrOptionNotFound {
+				t.Errorf("get on nil Options error = %v, want %v", err, ErrOptionNotFound)
+			}
+
+			// Set 在 Options 为 nil 时自动创建
+			tt.set(&o, 0xa5)
+			if v, err := tt.get(o); err != nil || v != 0xa5 {
+				t.Errorf("get = %d, %v, want 165", v, err)
+			}
+			if tlv := o[tt.tag]; tlv == nil || tlv.Length != 1 || len(tlv.Value) != 1 {
+				t.Errorf("TLV = %+v, want length 1", tlv)
+			}
+			tt.set(&o, 0)
+			if v, err := tt.get(o); err != nil || v != 0 {
+				t.Errorf("get after overwrite = %d, %v, want 0", v, err)
+			}
+
+			// 对端发来的长度不符的 TLV
+			for _, value := range [][]byte{nil, {1, 2}} {
+				o := NewOptions(NewTLV(tt.tag, value))
+				if _, err := tt.get(o); errCause(err) != ErrLength {
+					t.Errorf("get with %d-byte value error = %v, want %v", len(value), err, ErrLength)
+				}
+			}
+		})
+	}
+}
+
+func TestStringOptions(t *testing.T) {
+	tests := []struct {
+		tag   Tag
+		get   func(Options) (string, error)
+		set   func(*Options, string) error
+		size  int
+		fixed bool
+	}{
+		{TAG_LinkID, Options.LinkID, (*Options).SetLinkID, 20, true},
+		{TAG_ChargeTermPseudo, Options.ChargeTermPseudo, (*Options).SetChargeTermPseudo, 32, false},
+		{TAG_DestTermPseudo, Options.DestTermPseudo, (*Options).SetDestTermPseudo, 32, false},
+		{TAG_SrcTermPseudo, Options.SrcTermPseudo, (*Options).SetSrcTermPseudo, 32, false},
+		{TAG_MsgSrc, Options.MsgSrc, (*Options).SetMsgSrc, 8, true},
+		{TAG_MServiceID, Options.MServiceID, (*Options).SetMServiceID, 21, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag.String(), func(t *testing.T) {
+			var o Options
+			if _, err := tt.get(o); errCause(err) != ErrOptionNotFound {
+				t.Errorf("get on nil Options error = %v, want %v", err, ErrOptionNotFound)
+			}
+
+			// 定长参数右补0x00，读取时去掉
+			if err := tt.set(&o, "abc"); err != nil {
+				t.Fatal(err)
+			}
+			if s, err := tt.get(o); err != nil || s != "abc" {
+				t.Errorf("get = %q, %v, want %q", s, err, "abc")
+			}
+			want := 3
+			if tt.fixed {
+				want = tt.size
+			}
+			if tlv := o[tt.tag]; int(tlv.Length) != want || len(tlv.Value) != want {
+				t.Errorf("TLV length %d (%d bytes), want %d", tlv.Length, len(tlv.Value), want)
+			}
+
+			full := strings.Repeat("x", tt.size)
+			if err := tt.set(&o, full); err != nil {
+				t.Fatal(err)
+			}
+			if s, err := tt.get(o); err != nil || s != full {
+				t.Errorf("get = %q, %v, want %q", s, err, full)
+			}
+
+			// 超长时返回错误且不修改原值
+			if err := tt.set(&o, full+"y"); errCause(err) != ErrLength {
+				t.Errorf("set %d bytes error = %v, want %v", tt.size+1, err, ErrLength)
+			}
+			if s, _ := tt.get(o); s != full {
+				t.Errorf("get after failed set = %q, want %q", s, full)
+			}
+
+			// 对端发来的长度不符的 TLV
+			bad := [][]byte{make([]byte, tt.size+1)}
+			if tt.fixed {
+				bad = append(bad, make([]byte, tt.size-1), nil)
+			}
+			for _, value := range bad {
+				o := NewOptions(NewTLV(tt.tag, value))
+				if _, err := tt.get(o); errCause(err) != ErrLength {
+					t.Errorf("get with %d-byte value error = %v, want %v", len(value), err, ErrLength)
+				}
+			}
+		})
+	}
+}
@@ -5,9 +5,13 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"sort"
 )
 
-var ErrLength = errors.New("Options: error length")
+var (
+	ErrLength         = errors.New("Options: error length")
+	ErrOptionNotFound = errors.New("Options: tag not found")
+)
 
 type Tag uint16
 
@@ -41,6 +45,7 @@ var TagName = map[Tag]string{
 	TAG_ChargeTermType:   "TAG_ChargeTermType",
 	TAG_ChargeTermPseudo: "TAG_ChargeTermPseudo",
 	TAG_DestTermType:     "TAG_DestTermType",
+	TAG_DestTermPseudo:   "TAG_DestTermPseudo",
 	TAG_PkTotal:          "TAG_PkTotal",
 	TAG_PkNumber:         "TAG_PkNumber",
 	TAG_SubmitMsgType:    "TAG_SubmitMsgType",
@@ -53,7 +58,55 @@ var TagName = map[Tag]string{
 	TAG_MServiceID:       "TAG_MServiceID",
 }
 
+func (t Tag) String() string {
+	if name, ok := TagName[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("TAG_0x%04x", uint16(t))
+}
+
+// 各可选参数 Value 的长度
+// fixed 为 true 时长度必须等于 size，否则不超过 size
+var tagSizes = map[Tag]struct {
+	size  int
+	fixed bool
+}{
+	TAG_TP_pid:           {1, true},
+	TAG_TP_udhi:          {1, true},
+	TAG_LinkID:           {20, true},
+	TAG_ChargeUserType:   {1, true},
+	TAG_ChargeTermType:   {1, true},
+	TAG_ChargeTermPseudo: {32, false},
+	TAG_DestTermType:     {1, true},
+	TAG_DestTermPseudo:   {32, false},
+	TAG_PkTotal:          {1, true},
+	TAG_PkNumber:         {1, true},
+	TAG_SubmitMsgType:    {1, true},
+	TAG_SPDealResult:     {1, true},
+	TAG_SrcTermType:      {1, true},
+	TAG_SrcTermPseudo:    {32, false},
+	TAG_NodesCount:       {1, true},
+	TAG_MsgSrc:           {8, true},
+	TAG_SrcType:          {1, true},
+	TAG_MServiceID:       {21, true},
+}
+
+// 检查可选参数长度，未定义的 Tag 不做检查
+func checkTagLength(tag Tag, length int) error {
+	sz, ok := tagSizes[tag]
+	if !ok {
+		return nil
+	}
+	if length > sz.size || (sz.fixed && length != sz.size) {
+		return NewOpError(ErrLength,
+			fmt.Sprintf("Options %v: length %d, expected %d", tag, length, sz.size))
+	}
+	return nil
+}
+
 // 可选参数 map
+// 打包时按 Tag 从小到大的顺序写出，保证每次编码结果一致，
+// 且 TP_pid、TP_udhi 总在最前。
 type Options map[Tag]*TLV
 
 // 返回可选字段部分的长度
@@ -67,10 +120,37 @@ func (o Options) Len() int {
 	return length
 }
 
+// 按编码顺序返回全部 Tag
+func (o Options) Tags() []Tag {
+	tags := make([]Tag, 0, len(o))
+	for t := range o {
+		tags = append(tags, t)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i] < tags[j] })
+	return tags
+}
+
+// 按编码顺序返回全部可选参数
+func (o Options) List() []*TLV {
+	list := make([]*TLV, 0, len(o))
+	for _, t := range o.Tags() {
+		list = append(list, o[t])
+	}
+	return list
+}
+
+func (o Options) Pack(w *pkgWriter) *pkgWriter {
+	for _, v := range o.List() {
+		b, _ := v.Byte()
+		w.WriteBytes(b)
+	}
+	return w
+}
+
 func (o Options) String() string {
 	var b bytes.Buffer
 
-	for _, v := range o {
+	for _, v := range o.List() {
 		fmt.Fprintln(&b, "--- Options ---")
 		fmt.Fprintln(&b, "Tag: ", v.Tag)
 		fmt.Fprintln(&b, "Length: ", v.Length)
@@ -79,10 +159,30 @@ func (o Options) String() string {
 	return b.String()
 }
 
+// 解析可选参数，同一 Tag 出现多次时以最后一次为准。
+// 需要原始顺序或重复的 Tag 时使用 ParseOptionList。
 func ParseOptions(rawData []byte) (Options, error) {
+	list, err := ParseOptionList(rawData)
+	if err != nil {
+		return nil, err
+	}
+	return NewOptions(list...), nil
+}
+
+// 由可选参数列表生成 Options，同一 Tag 以最后一个为准
+func NewOptions(tlvs ...*TLV) Options {
+	ops := make(Options, len(tlvs))
+	for _, t := range tlvs {
+		ops[t.Tag] = t
+	}
+	return ops
+}
+
+// 按原始顺序解析全部可选参数，保留重复的 Tag
+func ParseOptionList(rawData []byte) ([]*TLV, error) {
 	var (
 		p      = 0
-		ops    = make(Options)
+		list   []*TLV
 		length = len(rawData)
 	)
 
@@ -101,11 +201,12 @@ func ParseOptions(rawData []byte) (Options, error) {
 			return nil, ErrLength
 		}
 
-		value := rawData[p : p+int(vlen)]
+		// 复制一份，rawData 可能来自复用的读缓冲
+		value := append([]byte(nil), rawData[p:p+int(vlen)]...)
 		p += int(vlen)
 
-		ops[Tag(tag)] = NewTLV(Tag(tag), value)
+		list = append(list, NewTLV(Tag(tag), value))
 	}
 
-	return ops, nil
+	return list, nil
 }
@@ -0,0 +1,137 @@
+package pkg
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+var goldenTLVs = []*TLV{
+	NewTLV(TAG_TP_pid, []byte{0}),
+	NewTLV(TAG_TP_udhi, []byte{1}),
+	NewTLV(TAG_LinkID, []byte("1234567890abcdefghij")),
+	NewTLV(TAG_PkTotal, []byte{2}),
+	NewTLV(TAG_PkNumber, []byte{1}),
+	NewTLV(TAG_MsgSrc, []byte("SRC00001")),
+	NewTLV(Tag(0x0100), []byte("x")),
+}
+
+// goldenTLVs 按 Tag 从小到大编码的结果
+var goldenOptions = []byte("" +
+	"\x00\x01\x00\x01\x00" +
+	"\x00\x02\x00\x01\x01" +
+	"\x00\x03\x00\x141234567890abcdefghij" +
+	"\x00\x09\x00\x01\x02" +
+	"\x00\x0a\x00\x01\x01" +
+	"\x00\x10\x00\x08SRC00001" +
+	"\x01\x00\x00\x01x")
+
+func TestSubmitOptionsGolden(t *testing.T) {
+	var first []byte
+	for run := 0; run < 20; run++ {
+		// 每次以不同的顺序加入可选参数
+		tlvs := make([]*TLV, len(goldenTLVs))
+		for i, j := range rand.Perm(len(goldenTLVs)) {
+			tlvs[i] = goldenTLVs[j]
+		}
+		p := &SmgpSubmitReqPkt{
+			SrcTermID:       "10661",
+			DestTermIDCount: 1,
+			DestTermID:      []string{"8618000000001"},
+			MsgLength:       2,
+			MsgContent:      []byte("hi"),
+			Options:         NewOptions(tlvs...),
+		}
+		data, err := p.Pack(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasSuffix(data, goldenOptions) {
+			t.Fatalf("run %d: options packed as % x, want % x", run, data[len(data)-len(goldenOptions):], goldenOptions)
+		}
+		if first == nil {
+			first = data
+		} else if !bytes.Equal(data, first) {
+			t.Fatalf("run %d: packet differs from the first run", run)
+		}
+
+		var out SmgpSubmitReqPkt
+		if err := out.Unpack(data[HeaderPktLen:]); err != nil {
+			t.Fatal(err)
+		}
+		for i, tlv := range out.OptionList {
+			if tlv.Tag != goldenTLVs[i].Tag || !bytes.Equal(tlv.Value, goldenTLVs[i].Value) {
+				t.Fatalf("run %d: OptionList[%d] = %v %q, want %v", run, i, tlv.Tag, tlv.Value, goldenTLVs[i].Tag)
+			}
+		}
+	}
+}
+
+func TestParseOptionListDuplicates(t *testing.T) {
+	tlvs := []*TLV{
+		NewTLV(TAG_LinkID, []byte("aaaaaaaaaaaaaaaaaaaa")),
+		NewTLV(Tag(0x0100), []byte("x")),
+		NewTLV(TAG_TP_udhi, []byte{1}),
+		NewTLV(TAG_LinkID, []byte("bbbbbbbbbbbbbbbbbbbb")),
+		NewTLV(Tag(0x0100), nil),
+	}
+	var raw []byte
+	for _, tlv := range tlvs {
+		b, err := tlv.Byte()
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw = append(raw, b...)
+	}
+
+	checkList := func(name string, list []*TLV) {
+		t.Helper()
+		if len(list) != len(tlvs) {
+			t.Fatalf("%s: got %d TLVs, want %d", name, len(list), len(tlvs))
+		}
+		for i, tlv := range list {
+			if tlv.Tag != tlvs[i].Tag || !bytes.Equal(tlv.Value, tlvs[i].Value) {
+				t.Errorf("%s[%d] = %v %q, want %v %q", name, i, tlv.Tag, tlv.Value, tlvs[i].Tag, tlvs[i].Value)
+			}
+		}
+	}
+
+	list, err := ParseOptionList(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkList("ParseOptionList", list)
+
+	// Options 中同一 Tag 以最后一个为准
+	ops, err := ParseOptions(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := ops.LinkID(); id != "bbbbbbbbbbbbbbbbbbbb" || len(ops) != 3 || ops[Tag(0x0100)].Length != 0 {
+		t.Errorf("ParseOptions = %v", ops)
+	}
+
+	// Submit 解包后 OptionList 保留原始顺序与重复的 Tag
+	data, err := (&SmgpSubmitReqPkt{DestTermIDCount: 1, DestTermID: []string{"1"}}).Pack(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p SmgpSubmitReqPkt
+	if err := p.Unpack(append(data[HeaderPktLen:], raw...)); err != nil {
+		t.Fatal(err)
+	}
+	checkList("Submit.OptionList", p.OptionList)
+
+	// 解析的值不引用原始数据，第二个 LinkID 的值从第38字节开始
+	raw[38] = 'z'
+	if !bytes.Equal(list[3].Value, tlvs[3].Value) {
+		t.Error("ParseOptionList value aliases the input")
+	}
+
+	// 截断在 Tag、Length 与 Value 中
+	for _, n := range []int{1, 3, 10} {
+		if _, err := ParseOptionList(raw[:len(raw)-n]); err != ErrLength {
+			t.Errorf("ParseOptionList with %d bytes cut: error = %v, want %v", n, err, ErrLength)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	SmgpPaymentRequestReqPktLen  = HeaderPktLen + 150        //162d, 0xa2
+	SmgpPaymentRequestRespPktLen = HeaderPktLen + 10 + 1 + 4 //27d, 0x1b
+	SmgpPaymentAffirmReqPktLen   = HeaderPktLen + 54         //66d, 0x42
+	SmgpPaymentAffirmRespPktLen  = HeaderPktLen + 10 + 4     //26d, 0x1a
+)
+
+// 是否需要计费确认 ResultNotifyCode
+const (
+	NO_NEED_AFFIRM = 0 // 扣费完成，不需要后续 Payment_Affirm
+	NEED_AFFIRM    = 1 // 预扣费，下发结束后需要 Payment_Affirm 确认
+)
+
+// 预付费用户计费请求
+type SmgpPaymentRequestReqPkt struct {
+	MsgID        MsgID  // 短消息流水号
+	PayMsgType   uint8  // 计费消息类型，取值同 MsgType
+	ChargeTermID string // 计费用户号码
+	SPCode       string // SP服务代码
+	DestTermID   string // 短消息接收号码
+	ServiceID    string // 业务代码
+	FeeType      string // 收费类型
+	FeeCode      string // 资费代码
+	FixedFee     string // 包月费/封顶费
+	Priority     uint8  // 短消息发送优先级
+	MsgLength    uint8  // 短消息长度
+	AreaCode     string // 计费用户所属区号
+	SMGWNo       string // 网关代码
+	FwdSMGWNo    string // 前转网关代码
+	SMCNo        string // 短消息中心代码
+	RecvTime     string // 短消息接收时间
+	DoneTime     string // 短消息下发时间
+
+	// used in session
+	SequenceID uint32
+}
+
+// 根据 Submit 请求生成发往计费系统的 Payment_Request
+func NewPaymentRequest(submit *SmgpSubmitReqPkt, msgId MsgID, destTermId string) *SmgpPaymentRequestReqPkt {
+	chargeTermId := submit.ChargeTermID
+	if chargeTermId == "" {
+		chargeTermId = destTermId
+	}
+
+	return &SmgpPaymentRequestReqPkt{
+		MsgID:        msgId,
+		PayMsgType:   submit.MsgType,
+		ChargeTermID: chargeTermId,
+		SPCode:       submit.SrcTermID,
+		DestTermID:   destTermId,
+		ServiceID:    submit.ServiceID,
+		FeeType:      submit.FeeType,
+		FeeCode:      submit.FeeCode,
+		FixedFee:     submit.FixedFee,
+		Priority:     submit.Priority,
+		MsgLength:    submit.MsgLength,
+		RecvTime:     GenNowTimeYYYYStr(),
+	}
+}
+
+func (p *SmgpPaymentRequestReqPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpPaymentRequestReqPktLen)
+	// header
+	w.WriteHeader(SmgpPaymentRequestReqPktLen, seqId, SMGP_PAYMENT_REQUEST)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteBytes(p.MsgID[:])
+	w.WriteByte(p.PayMsgType)
+	w.WriteFixedSizeString(p.ChargeTermID, 21)
+	w.WriteFixedSizeString(p.SPCode, 21)
+	w.WriteFixedSizeString(p.DestTermID, 21)
+	w.WriteFixedSizeString(p.ServiceID, 10)
+	w.WriteFixedSizeString(p.FeeType, 2)
+	w.WriteFixedSizeString(p.FeeCode, 6)
+	w.WriteFixedSizeString(p.FixedFee, 6)
+	w.WriteByte(p.Priority)
+	w.WriteByte(p.MsgLength)
+	w.WriteFixedSizeString(p.AreaCode, 4)
+	w.WriteFixedSizeString(p.SMGWNo, 6)
+	w.WriteFixedSizeString(p.FwdSMGWNo, 6)
+	w.WriteFixedSizeString(p.SMCNo, 6)
+	w.WriteFixedSizeString(p.RecvTime, 14)
+	w.WriteFixedSizeString(p.DoneTime, 14)
+
+	return w.Bytes()
+}
+
+func (p *SmgpPaymentRequestReqPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	r.ReadBytes(p.MsgID[:])
+	p.PayMsgType = r.ReadByte()
+	p.ChargeTermID = string(r.ReadCString(21))
+	p.SPCode = string(r.ReadCString(21))
+	p.DestTermID = string(r.ReadCString(21))
+	p.ServiceID = string(r.ReadCString(10))
+	p.FeeType = string(r.ReadCString(2))
+	p.FeeCode = string(r.ReadCString(6))
+	p.FixedFee = string(r.ReadCString(6))
+	p.Priority = r.ReadByte()
+	p.MsgLength = r.ReadByte()
+	p.AreaCode = string(r.ReadCString(4))
+	p.SMGWNo = string(r.ReadCString(6))
+	p.FwdSMGWNo = string(r.ReadCString(6))
+	p.SMCNo = string(r.ReadCString(6))
+	p.RecvTime = string(r.ReadCString(14))
+	p.DoneTime = string(r.ReadCString(14))
+
+	return r.Error()
+}
+
+func (p *SmgpPaymentRequestReqPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Payment Request Req ---")
+	fmt.Fprintln(&b, "MsgID: ", p.MsgID)
+	fmt.Fprintln(&b, "PayMsgType: ", p.PayMsgType)
+	fmt.Fprintln(&b, "ChargeTermID: ", p.ChargeTermID)
+	fmt.Fprintln(&b, "SPCode: ", p.SPCode)
+	fmt.Fprintln(&b, "DestTermID: ", p.DestTermID)
+	fmt.Fprintln(&b, "ServiceID: ", p.ServiceID)
+	fmt.Fprintln(&b, "FeeType: ", p.FeeType)
+	fmt.Fprintln(&b, "FeeCode: ", p.FeeCode)
+	fmt.Fprintln(&b, "FixedFee: ", p.FixedFee)
+	fmt.Fprintln(&b, "Priority: ", p.Priority)
+	fmt.Fprintln(&b, "MsgLength: ", p.MsgLength)
+	fmt.Fprintln(&b, "AreaCode: ", p.AreaCode)
+	fmt.Fprintln(&b, "SMGWNo: ", p.SMGWNo)
+	fmt.Fprintln(&b, "FwdSMGWNo: ", p.FwdSMGWNo)
+	fmt.Fprintln(&b, "SMCNo: ", p.SMCNo)
+	fmt.Fprintln(&b, "RecvTime: ", p.RecvTime)
+	fmt.Fprintln(&b, "DoneTime: ", p.DoneTime)
+	return b.String()
+}
+
+func (p *SmgpPaymentRequestReqPkt) PrepareResponse(rsp Packer) {
+	if r, ok := rsp.(*SmgpPaymentRequestRespPkt); ok {
+		r.MsgID = p.MsgID
+	}
+}
+
+type SmgpPaymentRequestRespPkt struct {
+	MsgID            MsgID
+	ResultNotifyCode uint8 // 是否需要计费确认
+	Status           Status
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpPaymentRequestRespPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpPaymentRequestRespPktLen)
+	// header
+	w.WriteHeader(SmgpPaymentRequestRespPktLen, seqId, SMGP_PAYMENT_REQUEST_RESP)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteBytes(p.MsgID[:])
+	w.WriteByte(p.ResultNotifyCode)
+	w.WriteInt(binary.BigEndian, p.Status)
+
+	return w.Bytes()
+}
+
+func (p *SmgpPaymentRequestRespPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	r.ReadBytes(p.MsgID[:])
+	p.ResultNotifyCode = r.ReadByte()
+	r.ReadInt(binary.BigEndian, &p.Status)
+
+	return r.Error()
+}
+
+func (p *SmgpPaymentRequestRespPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Payment Request Resp ---")
+	fmt.Fprintln(&b, "MsgID: ", p.MsgID)
+	fmt.Fprintln(&b, "ResultNotifyCode: ", p.ResultNotifyCode)
+	fmt.Fprintln(&b, "Status: ", p.Status)
+	return b.String()
+}
+
+// 预付费用户计费确认，短消息下发结束后通知计费系统最终扣费或退费
+type SmgpPaymentAffirmReqPkt struct {
+	MsgID         MsgID  // 短消息流水号
+	PayMsgType    uint8  // 计费消息类型，取值同 MsgType
+	ChargeTermID  string // 计费用户号码
+	DestTermID    string // 短消息接收号码
+	DeliverResult uint8  // 下发结果，0 表示成功
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpPaymentAffirmReqPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpPaymentAffirmReqPktLen)
+	// header
+	w.WriteHeader(SmgpPaymentAffirmReqPktLen, seqId, SMGP_PAYMENT_AFFIRM)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteBytes(p.MsgID[:])
+	w.WriteByte(p.PayMsgType)
+	w.WriteFixedSizeString(p.ChargeTermID, 21)
+	w.WriteFixedSizeString(p.DestTermID, 21)
+	w.WriteByte(p.DeliverResult)
+
+	return w.Bytes()
+}
+
+func (p *SmgpPaymentAffirmReqPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	r.ReadBytes(p.MsgID[:])
+	p.PayMsgType = r.ReadByte()
+	p.ChargeTermID = string(r.ReadCString(21))
+	p.DestTermID = string(r.ReadCString(21))
+	p.DeliverResult = r.ReadByte()
+
+	return r.Error()
+}
+
+func (p *SmgpPaymentAffirmReqPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Payment Affirm Req ---")
+	fmt.Fprintln(&b, "MsgID: ", p.MsgID)
+	fmt.Fprintln(&b, "PayMsgType: ", p.PayMsgType)
+	fmt.Fprintln(&b, "ChargeTermID: ", p.ChargeTermID)
+	fmt.Fprintln(&b, "DestTermID: ", p.DestTermID)
+	fmt.Fprintln(&b, "DeliverResult: ", p.DeliverResult)
+	return b.String()
+}
+
+func (p *SmgpPaymentAffirmReqPkt) PrepareResponse(rsp Packer) {
+	if r, ok := rsp.(*SmgpPaymentAffirmRespPkt); ok {
+		r.MsgID = p.MsgID
+	}
+}
+
+type SmgpPaymentAffirmRespPkt struct {
+	MsgID  MsgID
+	Status Status
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpPaymentAffirmRespPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpPaymentAffirmRespPktLen)
+	// header
+	w.WriteHeader(SmgpPaymentAffirmRespPktLen, seqId, SMGP_PAYMENT_AFFIRM_RESP)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteBytes(p.MsgID[:])
+	w.WriteInt(binary.BigEndian, p.Status)
+
+	return w.Bytes()
+}
+
+func (p *SmgpPaymentAffirmRespPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	r.ReadBytes(p.MsgID[:])
+	r.ReadInt(binary.BigEndian, &p.Status)
+
+	return r.Error()
+}
+
+func (p *SmgpPaymentAffirmRespPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Payment Affirm Resp ---")
+	fmt.Fprintln(&b, "MsgID: ", p.MsgID)
+	fmt.Fprintln(&b, "Status: ", p.Status)
+	return b.String()
+}
@@ -0,0 +1,40 @@
+package pkg
+
+import "github.com/boxtsecond/gosmgp/pkg/udh"
+
+// 生成发往终端应用端口的二进制短消息，每段带16位端口寻址信息元素，
+// 超长时自动分段，参考号取自 refs，为空时使用包内默认分配器
+func NewPortSubmits(tmpl *SmgpSubmitReqPkt, dstPort, srcPort uint16, data []byte, refs *RefAllocator) ([]*SmgpSubmitReqPkt, error) {
+	s := &Splitter{
+		MsgFormat: BINARY,
+		Refs:      refs,
+		IEs:       udh.Header{udh.Port16(dstPort, srcPort)},
+	}
+	segments, err := s.Split(data)
+	if err != nil {
+		return nil, err
+	}
+	text := &EncodedText{MsgFormat: BINARY, Segments: segments, udhi: true}
+	return text.Submits(tmpl), nil
+}
+
+// 生成 WAP Push，pdu 为已编码的 WSP Push PDU
+func NewWapPushSubmits(tmpl *SmgpSubmitReqPkt, pdu []byte, refs *RefAllocator) ([]*SmgpSubmitReqPkt, error) {
+	return NewPortSubmits(tmpl, udh.PORT_WAP_PUSH, udh.PORT_WAP_WSP, pdu, refs)
+}
+
+// 按 TP_udhi 解析内容中的 UDH，返回 UDH 与其后的内容；TP_udhi 不为1时 UDH 为空
+func splitUDH(options Options, content []byte) (udh.Header, []byte, error) {
+	if udhi, _ := options.TPUdhi(); udhi != 1 {
+		return nil, content, nil
+	}
+	return udh.Parse(content)
+}
+
+func (p *SmgpSubmitReqPkt) UDH() (udh.Header, []byte, error) {
+	return splitUDH(p.Options, p.MsgContent)
+}
+
+func (p *SmgpDeliverReqPkt) UDH() (udh.Header, []byte, error) {
+	return splitUDH(p.Options, p.MsgContent)
+}
@@ -0,0 +1,97 @@
+package pkg
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/boxtsecond/gosmgp/pkg/udh"
+)
+
+func TestNewPortSubmits(t *testing.T) {
+	tmpl := &SmgpSubmitReqPkt{SrcTermID: "10661", DestTermIDCount: 1, DestTermID: []string{"8618000000001"}}
+	port := udh.Port16(0x1580, 0x23f0)
+
+	tests := []struct {
+		name   string
+		size   int
+		bodies []int
+	}{
+		{"empty", 0, []int{0}},
+		// 端口 UDH 7字节，单条最多133字节
+		{"single", 133, []int{133}},
+		// 分段时 UDH 加上8位长短信信息元素共12字节，每段128字节
+		{"two segments", 134, []int{128, 6}},
+		{"three segments", 300, []int{128, 128, 44}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make([]byte, tt.size)
+			for i := range data {
+				data[i] = byte(i)
+			}
+			ps, err := NewPortSubmits(tmpl, 0x1580, 0x23f0, data, NewRefAllocator())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ps) != len(tt.bodies) {
+				t.Fatalf("got %d submits, want %d", len(ps), len(tt.bodies))
+			}
+
+			var joined []byte
+			var ref uint16
+			for i, p := range ps {
+				if p.MsgFormat != BINARY || int(p.MsgLength) != len(p.MsgContent) || len(p.MsgContent) > MaxSmsLength {
+					t.Errorf("submit %d: MsgFormat %d, MsgLength %d, %d bytes", i+1, p.MsgFormat, p.MsgLength, len(p.MsgContent))
+				}
+				udhi, _ := p.Options.TPUdhi()
+				total, _ := p.Options.PkTotal()
+				number, _ := p.Options.PkNumber()
+				if udhi != 1 || int(total) != len(ps) || int(number) != i+1 {
+					t.Errorf("submit %d options: udhi %d, %d/%d", i+1, udhi, number, total)
+				}
+
+				h, body, err := p.UDH()
+				if err != nil {
+					t.Fatalf("submit %d: %v", i+1, err)
+				}
+				if len(body) != tt.bodies[i] {
+					t.Errorf("submit %d body = %d bytes, want %d", i+1, len(body), tt.bodies[i])
+				}
+				joined = append(joined, body...)
+
+				if len(ps) == 1 {
+					if len(h) != 1 || h[0].ID != port.ID || !bytes.Equal(h[0].Data, port.Data) {
+						t.Errorf("UDH = %v, want only %v", h, port)
+					}
+					continue
+				}
+				if len(h) != 2 || h[0].ID != port.ID || !bytes.Equal(h[0].Data, port.Data) {
+					t.Fatalf("submit %d UDH = %v, want %v first", i+1, h, port)
+				}
+				c, ok := h.Concat()
+				if !ok || c.Ref16 || int(c.Total) != len(ps) || int(c.Seq) != i+1 {
+					t.Fatalf("submit %d concat = %+v, %v", i+1, c, ok)
+				}
+				if i == 0 {
+					ref = c.Ref
+				} else if c.Ref != ref {
+					t.Errorf("submit %d ref = %d, want %d", i+1, c.Ref, ref)
+				}
+			}
+			if !bytes.Equal(joined, data) {
+				t.Error("joined segments differ from the data")
+			}
+		})
+	}
+}
+
+func TestNewWapPushSubmits(t *testing.T) {
+	ps, err := NewWapPushSubmits(&SmgpSubmitReqPkt{}, []byte{0x01, 0x06}, nil)
+	if err != nil || len(ps) != 1 {
+		t.Fatalf("NewWapPushSubmits = %d submits, %v", len(ps), err)
+	}
+	want := []byte{6, udh.IEI_PORT_16, 4, 0x0b, 0x84, 0x23, 0xf0, 0x01, 0x06}
+	if !bytes.Equal(ps[0].MsgContent, want) {
+		t.Errorf("MsgContent = % x, want % x", ps[0].MsgContent, want)
+	}
+}
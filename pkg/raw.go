@@ -0,0 +1,49 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// 未解析的原始包，保留原始包头与包体，可原样重新打包。
+// 用于代理、抓包等需要透传未知命令的场景。
+type RawPkt struct {
+	Header Header
+	Body   []byte
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *RawPkt) RequestID() RequestID {
+	return RequestID(p.Header.RequestID)
+}
+
+func (p *RawPkt) Pack(seqId uint32) ([]byte, error) {
+	var pktLen = HeaderPktLen + uint32(len(p.Body))
+	var w = newPkgWriter(pktLen)
+	// header
+	w.WriteHeader(pktLen, seqId, RequestID(p.Header.RequestID))
+	p.Header.PacketLength = pktLen
+	p.Header.SequenceID = seqId
+	p.SequenceID = seqId
+
+	// body
+	w.WriteBytes(p.Body)
+
+	return w.Bytes()
+}
+
+// data 为包体，会被复制保存
+func (p *RawPkt) Unpack(data []byte) error {
+	p.Body = append([]byte(nil), data...)
+	return nil
+}
+
+func (p *RawPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Raw Packet ---")
+	fmt.Fprint(&b, p.Header.String())
+	fmt.Fprintf(&b, "Body: %x\n", p.Body)
+	return b.String()
+}
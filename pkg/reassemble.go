@@ -0,0 +1,302 @@
+package pkg
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/boxtsecond/gosmgp/pkg/udh"
+)
+
+var ErrReassembleLimit = errors.New("reassembler: segment exceeds memory limit")
+
+const (
+	DefaultReassembleTimeout     = 3 * time.Minute
+	DefaultReassembleMaxMessages = 1024
+	DefaultReassembleMaxBytes    = 4 << 20
+)
+
+// 长短信分段信息
+type concatInfo struct {
+	ref    uint16
+	ref16  bool
+	tlv    bool   // 分段信息来自 TAG_PkTotal/TAG_PkNumber
+	linkID string // tlv 为 true 时用于区分同一号码对的不同长短信
+	total  uint8
+	seq    uint8
+	body   []byte // 去掉 UDH 后的内容
+}
+
+// 取 Deliver 的分段信息，状态报告不是长短信分段。
+// TP_udhi 为1时解析 UDH 中的 IEI 0x00(8位参考号)与 IEI 0x08(16位参考号)，
+// 否则使用 TAG_PkTotal/TAG_PkNumber，此时没有参考号，以 LinkID 区分
+func deliverConcatInfo(p *SmgpDeliverReqPkt) (concatInfo, bool) {
+	if p.IsReport == IS_REPORT {
+		return concatInfo{}, false
+	}
+	if udhi, _ := p.Options.TPUdhi(); udhi == 1 {
+		return parseConcatUDH(p.MsgContent)
+	}
+
+	total, err1 := p.Options.PkTotal()
+	seq, err2 := p.Options.PkNumber()
+	if err1 != nil || err2 != nil || total <= 1 || seq == 0 || seq > total {
+		return concatInfo{}, false
+	}
+	linkID, _ := p.Options.LinkID()
+	return concatInfo{tlv: true, linkID: linkID, total: total, seq: seq, body: p.MsgContent}, true
+}
+
+func parseConcatUDH(content []byte) (concatInfo, bool) {
+	h, body, err := udh.Parse(content)
+	if err != nil {
+		return concatInfo{}, false
+	}
+	c, ok := h.Concat()
+	if !ok || c.Total == 0 || c.Seq == 0 || c.Seq > c.Total {
+		return concatInfo{}, false
+	}
+	return concatInfo{ref: c.Ref, ref16: c.Ref16, total: c.Total, seq: c.Seq, body: body}, true
+}
+
+// 长短信重组结果
+type ReassembleResult struct {
+	SrcTermID  string
+	DestTermID string
+	Ref        uint16
+	Total      int
+
+	// 按分段序号排列，超时过期时未收到的分段为 nil
+	Parts []*SmgpDeliverReqPkt
+	// 去掉 UDH 后按序拼接的内容，仅在 Complete 为 true 时有效
+	Content []byte
+	// false 表示未收齐就已超时或被淘汰
+	Complete bool
+}
+
+type ReassembleFunc func(*ReassembleResult)
+
+type reassembleKey struct {
+	src, dest string
+	ref       uint16
+	ref16     bool
+	tlv       bool
+	linkID    string
+	total     uint8 // 仅 tlv 为 true 时使用
+}
+
+type partialMsg struct {
+	total   uint8
+	parts   []*SmgpDeliverReqPkt
+	bodies  [][]byte
+	got     int
+	size    int
+	created time.Time
+}
+
+// 上行长短信重组，按发送号码、接收号码和参考号归并分段；
+// 没有参考号的 TLV 分段按发送号码、接收号码、LinkID 和总分段数归并。
+// 同一分段序号收到不同 MsgID 的分段时视为参考号被复用，之前未收齐的消息作废。
+// 状态报告与非长短信分段直接作为完整消息回调。
+// 可并发使用，回调在调用 Add/Sweep 的 goroutine 中执行，且不持有锁。
+type Reassembler struct {
+	Timeout     time.Duration // 未收齐分段的保留时长
+	MaxMessages int           // 同时缓存的未完成长短信条数上限
+	MaxBytes    int           // 缓存分段内容总字节数上限
+	OnMessage   ReassembleFunc
+
+	mu      sync.Mutex
+	pending map[reassembleKey]*partialMsg
+	size    int
+}
+
+func NewReassembler(timeout time.Duration, fn ReassembleFunc) *Reassembler {
+	if timeout <= 0 {
+		timeout = DefaultReassembleTimeout
+	}
+	return &Reassembler{
+		Timeout:     timeout,
+		MaxMessages: DefaultReassembleMaxMessages,
+		MaxBytes:    DefaultReassembleMaxBytes,
+		OnMessage:   fn,
+		pending:     make(map[reassembleKey]*partialMsg),
+	}
+}
+
+// 加入一条 Deliver。非长短信分段直接作为完整消息回调；
+// 收齐全部分段时回调完整消息；超出内存限制时先淘汰最早的未完成消息。
+func (r *Reassembler) Add(p *SmgpDeliverReqPkt) error {
+	info, ok := deliverConcatInfo(p)
+	if !ok || info.total == 1 {
+		body := p.MsgContent
+		if ok {
+			body = info.body
+		}
+		r.emit([]*ReassembleResult{{
+			SrcTermID:  p.SrcTermID,
+			DestTermID: p.DestTermID,
+			Total:      1,
+			Parts:      []*SmgpDeliverReqPkt{p},
+			Content:    body,
+			Complete:   true,
+		}})
+		return nil
+	}
+
+	var results []*ReassembleResult
+	key := reassembleKey{src: p.SrcTermID, dest: p.DestTermID, ref: info.ref, ref16: info.ref16}
+	if info.tlv {
+		key.tlv, key.linkID, key.total = true, info.linkID, info.total
+	}
+	i := int(info.seq) - 1
+
+	r.mu.Lock()
+	if r.pending == nil {
+		r.pending = make(map[reassembleKey]*partialMsg)
+	}
+	now := time.Now()
+	results = r.sweepLocked(now, results)
+
+	if r.MaxBytes > 0 && len(info.body) > r.MaxBytes {
+		r.mu.Unlock()
+		r.emit(results)
+		return ErrReassembleLimit
+	}
+
+	m := r.pending[key]
+	if m != nil && (m.total != info.total || m.parts[i] != nil && m.parts[i].MsgID != p.MsgID) {
+		// 参考号被复用，之前未收齐的消息作废；MsgID 相同的是网关重发，替换即可
+		results = append(results, r.removeLocked(key, m, false))
+		m = nil
+	}
+	if m == nil {
+		for r.MaxMessages > 0 && len(r.pending) >= r.MaxMessages {
+			results = append(results, r.evictOldestLocked())
+		}
+		m = &partialMsg{
+			total:   info.total,
+			parts:   make([]*SmgpDeliverReqPkt, info.total),
+			bodies:  make([][]byte, info.total),
+			created: now,
+		}
+		r.pending[key] = m
+	}
+
+	if m.parts[i] == nil {
+		m.got++
+	} else {
+		m.size -= len(m.bodies[i])
+		r.size -= len(m.bodies[i])
+	}
+	m.parts[i], m.bodies[i] = p, info.body
+	m.size += len(info.body)
+	r.size += len(info.body)
+
+	for r.MaxBytes > 0 && r.size > r.MaxBytes && len(r.pending) > 1 {
+		results = append(results, r.evictOldestLocked())
+	}
+
+	if m.got == int(m.total) {
+		if r.pending[key] == m {
+			results = append(results, r.removeLocked(key, m, true))
+		}
+	}
+	r.mu.Unlock()
+
+	r.emit(results)
+	return nil
+}
+
+// 清理已超时的未完成消息并回调，返回清理的条数
+func (r *Reassembler) Sweep() int {
+	r.mu.Lock()
+	results := r.sweepLocked(time.Now(), nil)
+	r.mu.Unlock()
+
+	r.emit(results)
+	return len(results)
+}
+
+// 每隔 interval 调用一次 Sweep，直到 done 关闭
+func (r *Reassembler) Run(interval time.Duration, done <-chan struct{}) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+			r.Sweep()
+		}
+	}
+}
+
+// 当前缓存的未完成消息条数与字节数
+func (r *Reassembler) Pending() (int, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pending), r.size
+}
+
+func (r *Reassembler) sweepLocked(now time.Time, results []*ReassembleResult) []*ReassembleResult {
+	if r.Timeout <= 0 {
+		return results
+	}
+	var expired []reassembleKey
+	for k, m := range r.pending {
+		if now.Sub(m.created) >= r.Timeout {
+			expired = append(expired, k)
+		}
+	}
+	// 按接收时间先后回调
+	sort.Slice(expired, func(i, j int) bool {
+		return r.pending[expired[i]].created.Before(r.pending[expired[j]].created)
+	})
+	for _, k := range expired {
+		results = append(results, r.removeLocked(k, r.pending[k], false))
+	}
+	return results
+}
+
+func (r *Reassembler) evictOldestLocked() *ReassembleResult {
+	var oldest reassembleKey
+	var om *partialMsg
+	for k, m := range r.pending {
+		if om == nil || m.created.Before(om.created) {
+			oldest, om = k, m
+		}
+	}
+	return r.removeLocked(oldest, om, false)
+}
+
+func (r *Reassembler) removeLocked(k reassembleKey, m *partialMsg, complete bool) *ReassembleResult {
+	delete(r.pending, k)
+	r.size -= m.size
+
+	res := &ReassembleResult{
+		SrcTermID:  k.src,
+		DestTermID: k.dest,
+		Ref:        k.ref,
+		Total:      int(m.total),
+		Parts:      m.parts,
+		Complete:   complete,
+	}
+	if complete {
+		content := make([]byte, 0, m.size)
+		for _, b := range m.bodies {
+			content = append(content, b...)
+		}
+		res.Content = content
+	}
+	return res
+}
+
+func (r *Reassembler) emit(results []*ReassembleResult) {
+	if r.OnMessage == nil {
+		return
+	}
+	for _, res := range results {
+		r.OnMessage(res)
+	}
+}
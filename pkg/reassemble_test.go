@@ -0,0 +1,219 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/boxtsecond/gosmgp/pkg/udh"
+)
+
+func testDeliver(n uint32, body []byte) *SmgpDeliverReqPkt {
+	id, _ := GenMsgID("10061", n)
+	return &SmgpDeliverReqPkt{MsgID: id, SrcTermID: "8618000000001", DestTermID: "10661", MsgLength: uint8(len(body)), MsgContent: body}
+}
+
+// 带 UDH 分段信息的 Deliver
+func udhSegment(n uint32, ie udh.IE, body string) *SmgpDeliverReqPkt {
+	h, _ := udh.Header{ie}.Bytes()
+	p := testDeliver(n, append(h, body...))
+	p.Options.SetTPUdhi(1)
+	return p
+}
+
+// 以 TAG_PkTotal/TAG_PkNumber 标明分段的 Deliver
+func tlvSegment(n uint32, linkID string, total, seq uint8, body string) *SmgpDeliverReqPkt {
+	p := testDeliver(n, []byte(body))
+	p.Options.SetPkTotal(total)
+	p.Options.SetPkNumber(seq)
+	if linkID != "" {
+		p.Options.SetLinkID(linkID)
+	}
+	return p
+}
+
+func collectReassembled(timeout time.Duration) (*Reassembler, *[]*ReassembleResult) {
+	var got []*ReassembleResult
+	return NewReassembler(timeout, func(res *ReassembleResult) { got = append(got, res) }), &got
+}
+
+func checkReassembled(t *testing.T, got []*ReassembleResult, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d results, want %d", len(got), len(want))
+	}
+	for i, res := range got {
+		if want[i] == "" {
+			if res.Complete {
+				t.Errorf("result %d complete with %q, want incomplete", i, res.Content)
+			}
+			continue
+		}
+		if !res.Complete || string(res.Content) != want[i] {
+			t.Errorf("result %d = %v %q, want complete %q", i, res.Complete, res.Content, want[i])
+		}
+	}
+}
+
+func TestReassembleRefs(t *testing.T) {
+	r, got := collectReassembled(0)
+	// 8位与16位参考号数值相同时也不能归并，分段乱序到达
+	segs := []*SmgpDeliverReqPkt{
+		udhSegment(1, udh.Concat8(7, 2, 2), "world"),
+		udhSegment(2, udh.Concat16(7, 3, 1), "foo"),
+		udhSegment(3, udh.Concat16(0x1207, 3, 3), "x"),
+		udhSegment(4, udh.Concat16(7, 3, 3), "baz"),
+		udhSegment(5, udh.Concat8(7, 2, 1), "hello "),
+		udhSegment(6, udh.Concat16(7, 3, 2), "bar"),
+	}
+	for _, p := range segs {
+		if err := r.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkReassembled(t, *got, "hello world", "foobarbaz")
+	if (*got)[0].Ref != 7 || (*got)[0].Total != 2 || (*got)[0].Parts[0] != segs[4] {
+		t.Errorf("8-bit result = %+v", (*got)[0])
+	}
+	if n, size := r.Pending(); n != 1 || size != 1 {
+		t.Errorf("pending = %d, %d bytes, want the 0x1207 segment", n, size)
+	}
+}
+
+func TestReassembleTLVSegments(t *testing.T) {
+	r, got := collectReassembled(0)
+	// 两条同时到达的 TLV 长短信以 LinkID 区分，不与 UDH 参考号0的分段归并
+	segs := []*SmgpDeliverReqPkt{
+		tlvSegment(1, "link-a", 2, 1, "a1"),
+		tlvSegment(2, "link-b", 2, 1, "b1"),
+		udhSegment(3, udh.Concat8(0, 2, 2), "u2"),
+		tlvSegment(4, "link-b", 2, 2, "b2"),
+		tlvSegment(5, "link-a", 2, 2, "a2"),
+	}
+	for _, p := range segs {
+		if err := r.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkReassembled(t, *got, "b1b2", "a1a2")
+	if n, _ := r.Pending(); n != 1 {
+		t.Errorf("pending = %d, want 1", n)
+	}
+}
+
+func TestReassembleSkipsReports(t *testing.T) {
+	r, got := collectReassembled(0)
+	for i := uint32(1); i <= 2; i++ {
+		p := tlvSegment(i, "", 2, 1, "report")
+		p.IsReport = IS_REPORT
+		if err := r.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkReassembled(t, *got, "report", "report")
+	if n, _ := r.Pending(); n != 0 {
+		t.Errorf("pending = %d, want 0", n)
+	}
+}
+
+func TestReassembleRefReuse(t *testing.T) {
+	r, got := collectReassembled(0)
+	steps := []struct {
+		p    *SmgpDeliverReqPkt
+		want []string
+	}{
+		{udhSegment(1, udh.Concat8(5, 2, 1), "old"), nil},
+		// 网关重发，MsgID 相同，替换即可
+		{udhSegment(1, udh.Concat8(5, 2, 1), "old"), nil},
+		// 同一序号、不同 MsgID：参考号已被新消息复用
+		{udhSegment(2, udh.Concat8(5, 2, 1), "new "), []string{""}},
+		{udhSegment(3, udh.Concat8(5, 2, 2), "msg"), []string{"", "new msg"}},
+		// 总分段数不同同样视为复用
+		{udhSegment(4, udh.Concat8(5, 3, 1), "a"), []string{"", "new msg"}},
+		{udhSegment(5, udh.Concat8(5, 2, 1), "b"), []string{"", "new msg", ""}},
+		// TLV 分段序号重复
+		{tlvSegment(6, "", 2, 2, "t2"), []string{"", "new msg", ""}},
+		{tlvSegment(7, "", 2, 2, "T2"), []string{"", "new msg", "", ""}},
+		{tlvSegment(8, "", 2, 1, "T1"), []string{"", "new msg", "", "", "T1T2"}},
+	}
+	for i, s := range steps {
+		if err := r.Add(s.p); err != nil {
+			t.Fatal(err)
+		}
+		if len(*got) != len(s.want) {
+			t.Fatalf("step %d: got %d results, want %d", i, len(*got), len(s.want))
+		}
+	}
+	checkReassembled(t, *got, steps[len(steps)-1].want...)
+	if old := (*got)[0]; old.Parts[0] != steps[1].p || old.Parts[1] != nil {
+		t.Errorf("discarded message parts = %v, want the resent segment only", old.Parts)
+	}
+}
+
+func TestReassembleTimeout(t *testing.T) {
+	r, got := collectReassembled(20 * time.Millisecond)
+	if err := r.Add(udhSegment(1, udh.Concat8(1, 2, 1), "first")); err != nil {
+		t.Fatal(err)
+	}
+	if n := r.Sweep(); n != 0 {
+		t.Fatalf("Sweep before timeout = %d, want 0", n)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if n := r.Sweep(); n != 1 {
+		t.Fatalf("Sweep after timeout = %d, want 1", n)
+	}
+	checkReassembled(t, *got, "")
+	res := (*got)[0]
+	if res.Ref != 1 || res.Total != 2 || res.Parts[0] == nil || res.Parts[1] != nil {
+		t.Errorf("expired result = %+v", res)
+	}
+
+	// 过期后到达的分段开始新消息，Add 时同样清理已过期的消息
+	r.Add(udhSegment(2, udh.Concat8(1, 2, 2), "second"))
+	time.Sleep(30 * time.Millisecond)
+	r.Add(udhSegment(3, udh.Concat8(2, 2, 1), "third"))
+	checkReassembled(t, *got, "", "")
+	if n, size := r.Pending(); n != 1 || size != len("third") {
+		t.Errorf("pending = %d, %d bytes, want 1, %d", n, size, len("third"))
+	}
+}
+
+func TestReassembleLimits(t *testing.T) {
+	t.Run("MaxMessages", func(t *testing.T) {
+		r, got := collectReassembled(0)
+		r.MaxMessages = 2
+		for ref := uint8(1); ref <= 3; ref++ {
+			r.Add(udhSegment(uint32(ref), udh.Concat8(ref, 2, 1), "x"))
+			time.Sleep(time.Millisecond)
+		}
+		checkReassembled(t, *got, "")
+		if (*got)[0].Ref != 1 {
+			t.Errorf("evicted ref %d, want the oldest 1", (*got)[0].Ref)
+		}
+		if n, _ := r.Pending(); n != 2 {
+			t.Errorf("pending = %d, want 2", n)
+		}
+	})
+
+	t.Run("MaxBytes", func(t *testing.T) {
+		r, got := collectReassembled(0)
+		r.MaxBytes = 10
+		r.Add(udhSegment(1, udh.Concat8(1, 2, 1), "123456"))
+		time.Sleep(time.Millisecond)
+		r.Add(udhSegment(2, udh.Concat8(2, 2, 1), "abcdef"))
+		checkReassembled(t, *got, "")
+		if (*got)[0].Ref != 1 {
+			t.Errorf("evicted ref %d, want the oldest 1", (*got)[0].Ref)
+		}
+		if n, size := r.Pending(); n != 1 || size != 6 {
+			t.Errorf("pending = %d, %d bytes, want 1, 6", n, size)
+		}
+
+		if err := r.Add(udhSegment(3, udh.Concat8(3, 2, 1), "0123456789a")); err != ErrReassembleLimit {
+			t.Errorf("Add oversized segment error = %v, want %v", err, ErrReassembleLimit)
+		}
+		if n, size := r.Pending(); n != 1 || size != 6 {
+			t.Errorf("pending after oversized segment = %d, %d bytes, want 1, 6", n, size)
+		}
+	})
+}
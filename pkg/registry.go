@@ -0,0 +1,87 @@
+package pkg
+
+// 根据 SequenceID 创建一个待解码的空包
+type PDUFactory func(seqId uint32) Packer
+
+var pduFactories = map[RequestID]PDUFactory{
+	SMGP_LOGIN:                     func(seq uint32) Packer { return &SmgpLoginReqPkt{SequenceID: seq} },
+	SMGP_LOGIN_RESP:                func(seq uint32) Packer { return &SmgpLoginRespPkt{SequenceID: seq} },
+	SMGP_SUBMIT:                    func(seq uint32) Packer { return &SmgpSubmitReqPkt{SequenceID: seq} },
+	SMGP_SUBMIT_RESP:               func(seq uint32) Packer { return &SmgpSubmitRespPkt{SequenceID: seq} },
+	SMGP_DELIVER:                   func(seq uint32) Packer { return &SmgpDeliverReqPkt{SequenceID: seq} },
+	SMGP_DELIVER_RESP:              func(seq uint32) Packer { return &SmgpDeliverRespPkt{SequenceID: seq} },
+	SMGP_ACTIVE_TEST:               func(seq uint32) Packer { return &SmgpActiveTestReqPkt{SequenceID: seq} },
+	SMGP_ACTIVE_TEST_RESP:          func(seq uint32) Packer { return &SmgpActiveTestRespPkt{SequenceID: seq} },
+	SMGP_FORWARD:                   func(seq uint32) Packer { return &SmgpForwardReqPkt{SequenceID: seq} },
+	SMGP_FORWARD_RESP:              func(seq uint32) Packer { return &SmgpForwardRespPkt{SequenceID: seq} },
+	SMGP_EXIT:                      func(seq uint32) Packer { return &SmgpExitReqPkt{SequenceID: seq} },
+	SMGP_EXIT_RESP:                 func(seq uint32) Packer { return &SmgpExitRespPkt{SequenceID: seq} },
+	SMGP_QUERY:                     func(seq uint32) Packer { return &SmgpQueryReqPkt{SequenceID: seq} },
+	SMGP_QUERY_RESP:                func(seq uint32) Packer { return &SmgpQueryRespPkt{SequenceID: seq} },
+	SMGP_QUERY_TE_ROUTE:            func(seq uint32) Packer { return &SmgpQueryTERouteReqPkt{SequenceID: seq} },
+	SMGP_QUERY_TE_ROUTE_RESP:       func(seq uint32) Packer { return &SmgpQueryTERouteRespPkt{SequenceID: seq} },
+	SMGP_QUERY_SP_ROUTE:            func(seq uint32) Packer { return &SmgpQuerySPRouteReqPkt{SequenceID: seq} },
+	SMGP_QUERY_SP_ROUTE_RESP:       func(seq uint32) Packer { return &SmgpQuerySPRouteRespPkt{SequenceID: seq} },
+	SMGP_PAYMENT_REQUEST:           func(seq uint32) Packer { return &SmgpPaymentRequestReqPkt{SequenceID: seq} },
+	SMGP_PAYMENT_REQUEST_RESP:      func(seq uint32) Packer { return &SmgpPaymentRequestRespPkt{SequenceID: seq} },
+	SMGP_PAYMENT_AFFIRM:            func(seq uint32) Packer { return &SmgpPaymentAffirmReqPkt{SequenceID: seq} },
+	SMGP_PAYMENT_AFFIRM_RESP:       func(seq uint32) Packer { return &SmgpPaymentAffirmRespPkt{SequenceID: seq} },
+	SMGP_QUERY_USERSTATE:           func(seq uint32) Packer { return &SmgpQueryUserStateReqPkt{SequenceID: seq} },
+	SMGP_QUERY_USERSTATE_RESP:      func(seq uint32) Packer { return &SmgpQueryUserStateRespPkt{SequenceID: seq} },
+	SMGP_GET_ALL_TE_ROUTE:          func(seq uint32) Packer { return &SmgpGetAllTERouteReqPkt{SequenceID: seq} },
+	SMGP_GET_ALL_TE_ROUTE_RESP:     func(seq uint32) Packer { return &SmgpGetAllTERouteRespPkt{SequenceID: seq} },
+	SMGP_GET_ALL_SP_ROUTE:          func(seq uint32) Packer { return &SmgpGetAllSPRouteReqPkt{SequenceID: seq} },
+	SMGP_GET_ALL_SP_ROUTE_RESP:     func(seq uint32) Packer { return &SmgpGetAllSPRouteRespPkt{SequenceID: seq} },
+	SMGP_UPDATE_TE_ROUTE:           func(seq uint32) Packer { return &SmgpUpdateTERouteReqPkt{SequenceID: seq} },
+	SMGP_UPDATE_TE_ROUTE_RESP:      func(seq uint32) Packer { return &SmgpUpdateTERouteRespPkt{SequenceID: seq} },
+	SMGP_UPDATE_SP_ROUTE:           func(seq uint32) Packer { return &SmgpUpdateSPRouteReqPkt{SequenceID: seq} },
+	SMGP_UPDATE_SP_ROUTE_RESP:      func(seq uint32) Packer { return &SmgpUpdateSPRouteRespPkt{SequenceID: seq} },
+	SMGP_PUSH_UPDATE_TE_ROUTE:      func(seq uint32) Packer { return &SmgpPushUpdateTERouteReqPkt{SequenceID: seq} },
+	SMGP_PUSH_UPDATE_TE_ROUTE_RESP: func(seq uint32) Packer { return &SmgpPushUpdateTERouteRespPkt{SequenceID: seq} },
+	SMGP_PUSH_UPDATE_SP_ROUTE:      func(seq uint32) Packer { return &SmgpPushUpdateSPRouteReqPkt{SequenceID: seq} },
+	SMGP_PUSH_UPDATE_SP_ROUTE_RESP: func(seq uint32) Packer { return &SmgpPushUpdateSPRouteRespPkt{SequenceID: seq} },
+}
+
+// 登记命令字的编解码，RecvAndUnpackPkt 与 server 均通过此处创建包。
+// 可用于支持厂商自定义命令，同一命令字重复登记时以最后一次为准。
+func RegisterPDU(id RequestID, factory func(seq uint32) Packer) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	pduFactories[id] = factory
+}
+
+// 创建命令字对应的空包，未登记时返回 ErrRequestIDNotSupported
+func NewPDU(id RequestID, seqId uint32) (Packer, error) {
+	registryMu.RLock()
+	factory, ok := pduFactories[id]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, ErrRequestIDNotSupported
+	}
+	return factory(seqId), nil
+}
+
+// 请求包实现该接口时，NewResponse 会用它预填应答包，如回填 MsgID
+type ResponsePreparer interface {
+	PrepareResponse(rsp Packer)
+}
+
+// 根据命令字配对创建请求 req 对应的应答包
+func NewResponse(id RequestID, req Packer, seqId uint32) (Packer, error) {
+	info, ok := LookupRequestID(id)
+	if !ok || info.IsResponse || info.Pair == 0 {
+		return nil, ErrRequestIDNotSupported
+	}
+
+	rsp, err := NewPDU(info.Pair, seqId)
+	if err != nil {
+		return nil, err
+	}
+
+	if p, ok := req.(ResponsePreparer); ok {
+		p.PrepareResponse(rsp)
+	}
+	return rsp, nil
+}
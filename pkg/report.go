@@ -0,0 +1,213 @@
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrReportInvalid = errors.New("status report content is invalid")
+
+const ReportTextLen = 20 // 状态报告 text 字段长度
+
+// 状态报告内容，格式为：
+// id:IIIIIIIIII sub:001 dlvrd:001 submit_date:YYMMDDhhmm done_date:YYMMDDhhmm stat:DDDDDDD err:E text:...
+type SmgpDeliverMsgContent struct {
+	SubmitMsgID MsgID // submit resp 的 MsgID
+	Sub         string
+	Dlvrd       string
+	SubmitDate  string
+	DoneDate    string
+	Stat        ReportStat
+	Err         ReportErr
+	Txt         string
+
+	// 未识别的字段，键为小写
+	Extra map[string]string
+}
+
+// 生成状态报告，text 为原短消息内容，只取前20字节
+func NewReport(submitMsgID MsgID, stat ReportStat, errCode ReportErr, submitTime, doneTime time.Time, text []byte) *SmgpDeliverMsgContent {
+	dlvrd := "000"
+	if stat.IsSuccess() {
+		dlvrd = "001"
+	}
+	if len(text) > ReportTextLen {
+		text = text[:ReportTextLen]
+	}
+	return &SmgpDeliverMsgContent{
+		SubmitMsgID: submitMsgID,
+		Sub:         "001",
+		Dlvrd:       dlvrd,
+		SubmitDate:  submitTime.Format("0601021504"),
+		DoneDate:    doneTime.Format("0601021504"),
+		Stat:        stat,
+		Err:         errCode,
+		Txt:         string(text),
+	}
+}
+
+// 按协议规定的字段宽度编码：sub、dlvrd、err 为3位数字，日期为 YYMMDDhhmm，
+// stat 为7字节，text 为20字节，不足以 0x00 填充
+func (p *SmgpDeliverMsgContent) Bytes() []byte {
+	var b bytes.Buffer
+	b.WriteString("id:")
+	b.Write(p.SubmitMsgID[:])
+	b.WriteString(" sub:")
+	b.WriteString(fixedReportField(p.Sub, 3, '0', true))
+	b.WriteString(" dlvrd:")
+	b.WriteString(fixedReportField(p.Dlvrd, 3, '0', true))
+	b.WriteString(" submit_date:")
+	b.WriteString(fixedReportField(p.SubmitDate, 10, '0', false))
+	b.WriteString(" done_date:")
+	b.WriteString(fixedReportField(p.DoneDate, 10, '0', false))
+	b.WriteString(" stat:")
+	b.WriteString(fixedReportField(string(p.Stat), 7, ' ', false))
+	b.WriteString(" err:")
+	b.WriteString(fixedReportField(string(p.Err), 3, '0', true))
+	b.WriteString(" text:")
+	b.WriteString(fixedReportField(p.Txt, ReportTextLen, 0x00, false))
+	return b.Bytes()
+}
+
+// Deprecated: 使用 Bytes
+func (p *SmgpDeliverMsgContent) Encode() string {
+	return string(p.Bytes())
+}
+
+func fixedReportField(s string, n int, pad byte, left bool) string {
+	if len(s) >= n {
+		return s[:n]
+	}
+	padding := strings.Repeat(string([]byte{pad}), n-len(s))
+	if left {
+		return padding + s
+	}
+	return s + padding
+}
+
+// 解析状态报告内容。字段按 key:value 解析，键不区分大小写，
+// "submit date" 与 "submit_date" 等同，text 取到内容末尾。
+// id 可以是10字节二进制、20位十六进制或十进制文本。
+func DecodeDeliverMsgContent(data []byte) (*SmgpDeliverMsgContent, error) {
+	s := string(bytes.TrimRight(data, "\x00"))
+	p := &SmgpDeliverMsgContent{}
+	hasID := false
+
+	for pos := 0; pos < len(s); {
+		if s[pos] == ' ' {
+			pos++
+			continue
+		}
+
+		colon := strings.IndexByte(s[pos:], ':')
+		if colon < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(s[pos : pos+colon]))
+		key = strings.Replace(key, " ", "_", -1)
+		pos += colon + 1
+
+		if key == "id" {
+			id, n, err := decodeReportID(s[pos:])
+			if err != nil {
+				return nil, err
+			}
+			p.SubmitMsgID = id
+			hasID = true
+			pos += n
+			continue
+		}
+
+		if key == "text" || key == "txt" {
+			p.Txt = s[pos:]
+			break
+		}
+
+		end := strings.IndexByte(s[pos:], ' ')
+		if end < 0 {
+			end = len(s) - pos
+		}
+		value := s[pos : pos+end]
+		pos += end
+
+		switch key {
+		case "sub":
+			p.Sub = value
+		case "dlvrd":
+			p.Dlvrd = value
+		case "submit_date":
+			p.SubmitDate = value
+		case "done_date":
+			p.DoneDate = value
+		case "stat":
+			p.Stat = ParseReportStat(value)
+		case "err":
+			p.Err = ReportErr(value)
+		default:
+			if p.Extra == nil {
+				p.Extra = make(map[string]string)
+			}
+			p.Extra[key] = value
+		}
+	}
+
+	if !hasID {
+		return nil, NewOpError(ErrReportInvalid, fmt.Sprintf("DecodeDeliverMsgContent: no id in %q", s))
+	}
+	return p, nil
+}
+
+// 解析 id 字段，返回 MsgID 及其在 s 中所占的长度
+func decodeReportID(s string) (MsgID, int, error) {
+	var id MsgID
+	end := strings.IndexByte(s, ' ')
+	if end < 0 {
+		end = len(s)
+	}
+	token := s[:end]
+
+	// 十六进制文本
+	if len(token) == 2*len(id) {
+		if id, err := ParseMsgID(token); err == nil {
+			return id, end, nil
+		}
+	}
+	// 10字节二进制，其中可能含有空格
+	if len(s) == len(id) || len(s) > len(id) && s[len(id)] == ' ' {
+		return MsgIDFromBytes([]byte(s[:len(id)])), len(id), nil
+	}
+	// 十进制文本，按BCD码左补0
+	if token != "" && len(token) < 2*len(id) && isDigits(token) {
+		id, err := ParseMsgID(strings.Repeat("0", 2*len(id)-len(token)) + token)
+		return id, end, err
+	}
+	return id, 0, NewOpError(ErrReportInvalid, fmt.Sprintf("DecodeDeliverMsgContent: invalid id %q", token))
+}
+
+func (p *SmgpDeliverMsgContent) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "")
+	fmt.Fprintln(&b, "\tID(SubmitMsgID): ", p.SubmitMsgID)
+	fmt.Fprintln(&b, "\tSub: ", p.Sub)
+	fmt.Fprintln(&b, "\tDlvrd: ", p.Dlvrd)
+	fmt.Fprintln(&b, "\tSubmitDate: ", p.SubmitDate)
+	fmt.Fprintln(&b, "\tDoneDate: ", p.DoneDate)
+	fmt.Fprintln(&b, "\tStat: ", p.Stat)
+	fmt.Fprintln(&b, "\tErr: ", p.Err)
+	fmt.Fprintln(&b, "\tTxt: ", p.Txt)
+
+	keys := make([]string, 0, len(p.Extra))
+	for k := range p.Extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\t%s:  %s\n", k, p.Extra[k])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package pkg
+
+import "strings"
+
+// 状态报告中的 stat 字段
+type ReportStat string
+
+const (
+	REPORT_DELIVRD ReportStat = "DELIVRD" // 已送达
+	REPORT_EXPIRED ReportStat = "EXPIRED" // 超过有效期
+	REPORT_DELETED ReportStat = "DELETED" // 已被删除
+	REPORT_UNDELIV ReportStat = "UNDELIV" // 无法送达
+	REPORT_ACCEPTD ReportStat = "ACCEPTD" // 已接收，待送达
+	REPORT_UNKNOWN ReportStat = "UNKNOWN" // 状态未知
+	REPORT_REJECTD ReportStat = "REJECTD" // 被拒绝
+)
+
+var reportStatDesc = map[ReportStat]string{
+	REPORT_DELIVRD: "已送达",
+	REPORT_EXPIRED: "超过有效期",
+	REPORT_DELETED: "已被删除",
+	REPORT_UNDELIV: "无法送达",
+	REPORT_ACCEPTD: "已接收，待送达",
+	REPORT_UNKNOWN: "状态未知",
+	REPORT_REJECTD: "被拒绝",
+}
+
+// 规整报告中的 stat，去除首尾空白与填充，并转为大写
+func ParseReportStat(s string) ReportStat {
+	return ReportStat(strings.ToUpper(strings.Trim(s, " \x00")))
+}
+
+// 是否为协议定义的状态，其余视为网关厂商自定义的错误码，如 MA:0001、MK:0005、MI:0013
+func (s ReportStat) IsStandard() bool {
+	_, ok := reportStatDesc[s]
+	return ok
+}
+
+// 是否为最终状态，ACCEPTD 与 UNKNOWN 之后可能还会有新的状态报告；
+// 厂商自定义的错误码均视为最终的失败状态
+func (s ReportStat) IsFinal() bool {
+	return s != "" && s != REPORT_ACCEPTD && s != REPORT_UNKNOWN
+}
+
+func (s ReportStat) IsSuccess() bool {
+	return s == REPORT_DELIVRD
+}
+
+func (s ReportStat) Description() string {
+	if d, ok := reportStatDesc[s]; ok {
+		return d
+	}
+	return "厂商自定义错误码"
+}
+
+func (s ReportStat) String() string {
+	return string(s)
+}
+
+// 状态报告中的 err 字段，3位数字
+type ReportErr string
+
+const (
+	REPORT_ERR_OK            ReportErr = "000" // 成功
+	REPORT_ERR_UNREACHABLE   ReportErr = "001" // 用户不能通信
+	REPORT_ERR_BUSY          ReportErr = "002" // 用户忙
+	REPORT_ERR_NO_COMPONENT  ReportErr = "003" // 终端无此部件号
+	REPORT_ERR_ILLEGAL_USER  ReportErr = "004" // 非法用户
+	REPORT_ERR_BLACKLIST     ReportErr = "005" // 用户在黑名单内
+	REPORT_ERR_SYSTEM        ReportErr = "006" // 系统错误
+	REPORT_ERR_MEMORY_FULL   ReportErr = "007" // 用户内存满
+	REPORT_ERR_NOT_MSG_TERM  ReportErr = "008" // 非信息终端
+	REPORT_ERR_DATA          ReportErr = "009" // 数据错误
+	REPORT_ERR_DATA_LOST     ReportErr = "010" // 数据丢失
+	REPORT_ERR_UNKNOWN_ERROR ReportErr = "999" // 未知错误
+)
+
+var reportErrDesc = map[ReportErr]string{
+	REPORT_ERR_OK:            "成功",
+	REPORT_ERR_UNREACHABLE:   "用户不能通信",
+	REPORT_ERR_BUSY:          "用户忙",
+	REPORT_ERR_NO_COMPONENT:  "终端无此部件号",
+	REPORT_ERR_ILLEGAL_USER:  "非法用户",
+	REPORT_ERR_BLACKLIST:     "用户在黑名单内",
+	REPORT_ERR_SYSTEM:        "系统错误",
+	REPORT_ERR_MEMORY_FULL:   "用户内存满",
+	REPORT_ERR_NOT_MSG_TERM:  "非信息终端",
+	REPORT_ERR_DATA:          "数据错误",
+	REPORT_ERR_DATA_LOST:     "数据丢失",
+	REPORT_ERR_UNKNOWN_ERROR: "未知错误",
+}
+
+func (e ReportErr) Description() string {
+	if d, ok := reportErrDesc[e]; ok {
+		return d
+	}
+	return "未定义的错误码"
+}
+
+func (e ReportErr) String() string {
+	return string(e)
+}
@@ -0,0 +1,127 @@
+package pkg
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+var testReportID, _ = ParseMsgID("01006101161700012345")
+
+func TestDecodeDeliverMsgContent(t *testing.T) {
+	binaryID := string(testReportID[:])
+	tests := []struct {
+		name    string
+		content string
+		stat    ReportStat
+		errCode ReportErr
+		txt     string
+	}{
+		{"binary id",
+			"id:" + binaryID + " sub:001 dlvrd:001 submit_date:2601161700 done_date:2601161701 stat:DELIVRD err:000 text:hello",
+			REPORT_DELIVRD, REPORT_ERR_OK, "hello"},
+		{"hex id",
+			"id:01006101161700012345 sub:001 dlvrd:000 submit_date:2601161700 done_date:2601161701 stat:UNDELIV err:001 text:",
+			REPORT_UNDELIV, "001", ""},
+		{"upper case keys with spaces",
+			"ID:01006101161700012345 Sub:001 Dlvrd:001 Submit Date:2601161700 Done Date:2601161701 Stat:DELIVRD Err:000 Text:a b:c",
+			REPORT_DELIVRD, REPORT_ERR_OK, "a b:c"},
+		{"missing fields",
+			"id:01006101161700012345 stat:EXPIRED",
+			REPORT_EXPIRED, "", ""},
+		{"trailing nul padding",
+			"id:01006101161700012345 stat:DELIVRD err:000 txt:abc\x00\x00\x00",
+			REPORT_DELIVRD, REPORT_ERR_OK, "abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := DecodeDeliverMsgContent([]byte(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.SubmitMsgID != testReportID {
+				t.Errorf("id = %s, want %s", r.SubmitMsgID, testReportID)
+			}
+			if r.Stat != tt.stat || r.Err != tt.errCode || r.Txt != tt.txt {
+				t.Errorf("got stat %q err %q text %q, want %q %q %q", r.Stat, r.Err, r.Txt, tt.stat, tt.errCode, tt.txt)
+			}
+		})
+	}
+}
+
+func TestDecodeDeliverMsgContentInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"stat:DELIVRD err:000",
+		"id:",
+		"id:xyz stat:DELIVRD",
+		"no colon at all",
+		":::::",
+	}
+	for _, content := range tests {
+		if _, err := DecodeDeliverMsgContent([]byte(content)); err == nil {
+			t.Errorf("DecodeDeliverMsgContent(%q) returned no error", content)
+		}
+	}
+}
+
+// 任意内容只能返回错误，不能 panic
+func TestDecodeDeliverMsgContentNoPanic(t *testing.T) {
+	full := NewReport(testReportID, REPORT_DELIVRD, REPORT_ERR_OK, time.Now(), time.Now(), []byte("hello")).Bytes()
+	var inputs [][]byte
+	for n := 0; n <= len(full); n++ {
+		inputs = append(inputs, full[:n], full[len(full)-n:])
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		b := append([]byte(nil), full...)
+		for j := rnd.Intn(8); j >= 0; j-- {
+			b[rnd.Intn(len(b))] = byte(rnd.Intn(256))
+		}
+		inputs = append(inputs, b[:rnd.Intn(len(b)+1)])
+	}
+
+	for _, b := range inputs {
+		func() {
+			defer func() {
+				if e := recover(); e != nil {
+					t.Errorf("DecodeDeliverMsgContent(%q) panics: %v", b, e)
+				}
+			}()
+			DecodeDeliverMsgContent(b)
+		}()
+	}
+}
+
+func TestDeliverUnpackMalformedReport(t *testing.T) {
+	tests := []struct {
+		content string
+		valid   bool
+	}{
+		{string(NewReport(testReportID, REPORT_DELIVRD, REPORT_ERR_OK, time.Now(), time.Now(), nil).Bytes()), true},
+		{"garbage", false},
+		{"id:", false},
+	}
+	for _, tt := range tests {
+		d := &SmgpDeliverReqPkt{
+			MsgID:      testReportID,
+			IsReport:   IS_REPORT,
+			SrcTermID:  "1",
+			DestTermID: "2",
+			MsgLength:  uint8(len(tt.content)),
+			MsgContent: []byte(tt.content),
+		}
+		data, err := d.Pack(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := &SmgpDeliverReqPkt{}
+		if err := out.Unpack(data[HeaderPktLen:]); err != nil {
+			t.Errorf("Unpack with report %q failed: %v", tt.content, err)
+			continue
+		}
+		if _, err := out.Report(); (err == nil) != tt.valid {
+			t.Errorf("Report() on %q error = %v, want valid %v", tt.content, err, tt.valid)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package pkg
 
+import (
+	"fmt"
+	"sync"
+)
+
 type RequestID uint32
 
 const (
@@ -27,51 +32,105 @@ const (
 	SMGP_REQUEST_MAX, SMGP_RESPONSE_MAX
 )
 
-func (id RequestID) String() string {
-	if id <= SMGP_QUERY && id > SMGP_REQUEST_MIN {
-		return []string{
-			"SMGP_LOGIN",
-			"SMGP_SUBMIT",
-			"SMGP_DELIVER",
-			"SMGP_ACTIVE_TEST",
-			"SMGP_FORWARD",
-			"SMGP_EXIT",
-			"SMGP_QUERY",
-		}[id-1]
-	} else if id < SMGP_REQUEST_MAX {
-		return []string{
-			"SMGP_QUERY_TE_ROUTE",
-			"SMGP_QUERY_SP_ROUTE",
-			"SMGP_PAYMENT_REQUEST",
-			"SMGP_PAYMENT_AFFIRM",
-			"SMGP_QUERY_USERSTATE",
-			"SMGP_GET_ALL_TE_ROUTE",
-			"SMGP_GET_ALL_SP_ROUTE",
-			"SMGP_UPDATE_TE_ROUTE",
-		}[id-0x00000008]
+// 命令字信息
+type RequestIDInfo struct {
+	Name       string
+	IsResponse bool      // 是否为应答命令
+	Pair       RequestID // 请求对应的应答命令，或应答对应的请求命令
+}
+
+var (
+	registryMu     sync.RWMutex
+	requestIDTable = newRequestIDTable()
+)
+
+func newRequestIDTable() map[RequestID]RequestIDInfo {
+	table := make(map[RequestID]RequestIDInfo)
+	for _, r := range []struct {
+		req, resp RequestID
+		name      string
+	}{
+		{SMGP_LOGIN, SMGP_LOGIN_RESP, "SMGP_LOGIN"},
+		{SMGP_SUBMIT, SMGP_SUBMIT_RESP, "SMGP_SUBMIT"},
+		{SMGP_DELIVER, SMGP_DELIVER_RESP, "SMGP_DELIVER"},
+		{SMGP_ACTIVE_TEST, SMGP_ACTIVE_TEST_RESP, "SMGP_ACTIVE_TEST"},
+		{SMGP_FORWARD, SMGP_FORWARD_RESP, "SMGP_FORWARD"},
+		{SMGP_EXIT, SMGP_EXIT_RESP, "SMGP_EXIT"},
+		{SMGP_QUERY, SMGP_QUERY_RESP, "SMGP_QUERY"},
+		{SMGP_QUERY_TE_ROUTE, SMGP_QUERY_TE_ROUTE_RESP, "SMGP_QUERY_TE_ROUTE"},
+		{SMGP_QUERY_SP_ROUTE, SMGP_QUERY_SP_ROUTE_RESP, "SMGP_QUERY_SP_ROUTE"},
+		{SMGP_PAYMENT_REQUEST, SMGP_PAYMENT_REQUEST_RESP, "SMGP_PAYMENT_REQUEST"},
+		{SMGP_PAYMENT_AFFIRM, SMGP_PAYMENT_AFFIRM_RESP, "SMGP_PAYMENT_AFFIRM"},
+		{SMGP_QUERY_USERSTATE, SMGP_QUERY_USERSTATE_RESP, "SMGP_QUERY_USERSTATE"},
+		{SMGP_GET_ALL_TE_ROUTE, SMGP_GET_ALL_TE_ROUTE_RESP, "SMGP_GET_ALL_TE_ROUTE"},
+		{SMGP_GET_ALL_SP_ROUTE, SMGP_GET_ALL_SP_ROUTE_RESP, "SMGP_GET_ALL_SP_ROUTE"},
+		{SMGP_UPDATE_TE_ROUTE, SMGP_UPDATE_TE_ROUTE_RESP, "SMGP_UPDATE_TE_ROUTE"},
+		{SMGP_UPDATE_SP_ROUTE, SMGP_UPDATE_SP_ROUTE_RESP, "SMGP_UPDATE_SP_ROUTE"},
+		{SMGP_PUSH_UPDATE_TE_ROUTE, SMGP_PUSH_UPDATE_TE_ROUTE_RESP, "SMGP_PUSH_UPDATE_TE_ROUTE"},
+		{SMGP_PUSH_UPDATE_SP_ROUTE, SMGP_PUSH_UPDATE_SP_ROUTE_RESP, "SMGP_PUSH_UPDATE_SP_ROUTE"},
+	} {
+		table[r.req] = RequestIDInfo{Name: r.name, Pair: r.resp}
+		table[r.resp] = RequestIDInfo{Name: r.name + "_RESP", IsResponse: true, Pair: r.req}
 	}
+	return table
+}
+
+// 登记一对命令字，用于厂商自定义命令。
+// resp 为0时表示该请求没有应答。
+func RegisterRequestID(req, resp RequestID, name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
 
-	if id <= SMGP_QUERY_RESP && id > SMGP_RESPONSE_MIN {
-		return []string{
-			"SMGP_LOGIN_RESP",
-			"SMGP_SUBMIT_RESP",
-			"SMGP_DELIVER_RESP",
-			"SMGP_ACTIVE_TEST_RESP",
-			"SMGP_FORWARD_RESP",
-			"SMGP_EXIT_RESP",
-			"SMGP_QUERY_RESP",
-		}[id-0x80000001]
-	} else if id < SMGP_RESPONSE_MAX {
-		return []string{
-			"SMGP_QUERY_TE_ROUTE_RESP",
-			"SMGP_QUERY_SP_ROUTE_RESP",
-			"SMGP_PAYMENT_REQUEST_RESP",
-			"SMGP_PAYMENT_AFFIRM_RESP",
-			"SMGP_QUERY_USERSTATE_RESP",
-			"SMGP_GET_ALL_TE_ROUTE_RESP",
-			"SMGP_GET_ALL_SP_ROUTE_RESP",
-			"SMGP_UPDATE_TE_ROUTE_RESP",
-		}[id-0x80000008]
+	requestIDTable[req] = RequestIDInfo{Name: name, Pair: resp}
+	if resp != 0 {
+		requestIDTable[resp] = RequestIDInfo{Name: name + "_RESP", IsResponse: true, Pair: req}
+	}
+}
+
+// 查询命令字信息，未知命令字返回 false
+func LookupRequestID(id RequestID) (RequestIDInfo, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	info, ok := requestIDTable[id]
+	return info, ok
+}
+
+// 是否为已登记的命令字
+func (id RequestID) Valid() bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	if _, ok := requestIDTable[id]; ok {
+		return true
+	}
+	_, ok := pduFactories[id]
+	return ok
+}
+
+func (id RequestID) IsResponse() bool {
+	info, _ := LookupRequestID(id)
+	return info.IsResponse
+}
+
+// 请求对应的应答命令字，或应答对应的请求命令字；未知命令字返回0
+func (id RequestID) Pair() RequestID {
+	info, _ := LookupRequestID(id)
+	return info.Pair
+}
+
+// 是否已通过 RegisterPDU 登记编解码
+func (id RequestID) Implemented() bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	_, ok := pduFactories[id]
+	return ok
+}
+
+func (id RequestID) String() string {
+	if info, ok := LookupRequestID(id); ok {
+		return info.Name
 	}
-	return "unknown"
+	return fmt.Sprintf("unknown(0x%08x)", uint32(id))
 }
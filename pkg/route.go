@@ -0,0 +1,291 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	TERoutePktLen = 4 + 21 + 4 + 1 + 6 + 15 + 2         //53d
+	SPRoutePktLen = 4 + 8 + 21 + 1 + 1 + 4 + 6 + 15 + 2 //62d
+
+	SmgpQueryTERouteReqPktLen  = HeaderPktLen + 6 + 21                 //39d, 0x27
+	SmgpQueryTERouteRespPktLen = HeaderPktLen + 4 + 21 + TERoutePktLen //90d, 0x5a
+	SmgpQuerySPRouteReqPktLen  = HeaderPktLen + 6 + 21                 //39d, 0x27
+	SmgpQuerySPRouteRespPktLen = HeaderPktLen + 4 + 21 + SPRoutePktLen //99d, 0x63
+)
+
+// 终端路由记录：号码段 TermRangeID 归属于网关 DestGatewayID
+type TERoute struct {
+	RouteID         uint32 // 路由编号
+	TermRangeID     string // 路由号码段
+	ProvinceCode    string // 终端所属省代码
+	UserType        uint8  // 用户类型
+	DestGatewayID   string // 目标网关代码
+	DestGatewayIP   string // 目标网关IP
+	DestGatewayPort uint16 // 目标网关端口
+}
+
+func (p *TERoute) Pack(w *pkgWriter) *pkgWriter {
+	w.WriteInt(binary.BigEndian, p.RouteID)
+	w.WriteFixedSizeString(p.TermRangeID, 21)
+	w.WriteFixedSizeString(p.ProvinceCode, 4)
+	w.WriteByte(p.UserType)
+	w.WriteFixedSizeString(p.DestGatewayID, 6)
+	w.WriteFixedSizeString(p.DestGatewayIP, 15)
+	w.WriteInt(binary.BigEndian, p.DestGatewayPort)
+	return w
+}
+
+func (p *TERoute) Unpack(r *pkgReader) *TERoute {
+	r.ReadInt(binary.BigEndian, &p.RouteID)
+	p.TermRangeID = string(r.ReadCString(21))
+	p.ProvinceCode = string(r.ReadCString(4))
+	p.UserType = r.ReadByte()
+	p.DestGatewayID = string(r.ReadCString(6))
+	p.DestGatewayIP = string(r.ReadCString(15))
+	r.ReadInt(binary.BigEndian, &p.DestGatewayPort)
+	return p
+}
+
+func (p *TERoute) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "")
+	fmt.Fprintln(&b, "\tRouteID: ", p.RouteID)
+	fmt.Fprintln(&b, "\tTermRangeID: ", p.TermRangeID)
+	fmt.Fprintln(&b, "\tProvinceCode: ", p.ProvinceCode)
+	fmt.Fprintln(&b, "\tUserType: ", p.UserType)
+	fmt.Fprintln(&b, "\tDestGatewayID: ", p.DestGatewayID)
+	fmt.Fprintln(&b, "\tDestGatewayIP: ", p.DestGatewayIP)
+	fmt.Fprintln(&b, "\tDestGatewayPort: ", p.DestGatewayPort)
+	return b.String()
+}
+
+// SP路由记录：SP服务代码 SPCode 归属于网关 DestGatewayID
+type SPRoute struct {
+	RouteID         uint32 // 路由编号
+	SPID            string // SP企业代码
+	SPCode          string // SP服务代码(接入号)
+	SPAccessType    uint8  // SP接入类型
+	SPType          uint8  // SP类型
+	ProvinceCode    string // SP所属省代码
+	DestGatewayID   string // 目标网关代码
+	DestGatewayIP   string // 目标网关IP
+	DestGatewayPort uint16 // 目标网关端口
+}
+
+func (p *SPRoute) Pack(w *pkgWriter) *pkgWriter {
+	w.WriteInt(binary.BigEndian, p.RouteID)
+	w.WriteFixedSizeString(p.SPID, 8)
+	w.WriteFixedSizeString(p.SPCode, 21)
+	w.WriteByte(p.SPAccessType)
+	w.WriteByte(p.SPType)
+	w.WriteFixedSizeString(p.ProvinceCode, 4)
+	w.WriteFixedSizeString(p.DestGatewayID, 6)
+	w.WriteFixedSizeString(p.DestGatewayIP, 15)
+	w.WriteInt(binary.BigEndian, p.DestGatewayPort)
+	return w
+}
+
+func (p *SPRoute) Unpack(r *pkgReader) *SPRoute {
+	r.ReadInt(binary.BigEndian, &p.RouteID)
+	p.SPID = string(r.ReadCString(8))
+	p.SPCode = string(r.ReadCString(21))
+	p.SPAccessType = r.ReadByte()
+	p.SPType = r.ReadByte()
+	p.ProvinceCode = string(r.ReadCString(4))
+	p.DestGatewayID = string(r.ReadCString(6))
+	p.DestGatewayIP = string(r.ReadCString(15))
+	r.ReadInt(binary.BigEndian, &p.DestGatewayPort)
+	return p
+}
+
+func (p *SPRoute) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "")
+	fmt.Fprintln(&b, "\tRouteID: ", p.RouteID)
+	fmt.Fprintln(&b, "\tSPID: ", p.SPID)
+	fmt.Fprintln(&b, "\tSPCode: ", p.SPCode)
+	fmt.Fprintln(&b, "\tSPAccessType: ", p.SPAccessType)
+	fmt.Fprintln(&b, "\tSPType: ", p.SPType)
+	fmt.Fprintln(&b, "\tProvinceCode: ", p.ProvinceCode)
+	fmt.Fprintln(&b, "\tDestGatewayID: ", p.DestGatewayID)
+	fmt.Fprintln(&b, "\tDestGatewayIP: ", p.DestGatewayIP)
+	fmt.Fprintln(&b, "\tDestGatewayPort: ", p.DestGatewayPort)
+	return b.String()
+}
+
+// 查询终端号码所属网关
+type SmgpQueryTERouteReqPkt struct {
+	SrcGatewayID string // 源网关代码
+	QueryTermID  string // 查询号码
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpQueryTERouteReqPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpQueryTERouteReqPktLen)
+	// header
+	w.WriteHeader(SmgpQueryTERouteReqPktLen, seqId, SMGP_QUERY_TE_ROUTE)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteFixedSizeString(p.SrcGatewayID, 6)
+	w.WriteFixedSizeString(p.QueryTermID, 21)
+
+	return w.Bytes()
+}
+
+func (p *SmgpQueryTERouteReqPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	p.SrcGatewayID = string(r.ReadCString(6))
+	p.QueryTermID = string(r.ReadCString(21))
+
+	return r.Error()
+}
+
+func (p *SmgpQueryTERouteReqPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Query TE Route Req ---")
+	fmt.Fprintln(&b, "SrcGatewayID: ", p.SrcGatewayID)
+	fmt.Fprintln(&b, "QueryTermID: ", p.QueryTermID)
+	return b.String()
+}
+
+func (p *SmgpQueryTERouteReqPkt) PrepareResponse(rsp Packer) {
+	if r, ok := rsp.(*SmgpQueryTERouteRespPkt); ok {
+		r.QueryTermID = p.QueryTermID
+	}
+}
+
+type SmgpQueryTERouteRespPkt struct {
+	Status      Status
+	QueryTermID string
+	Route       TERoute
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpQueryTERouteRespPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpQueryTERouteRespPktLen)
+	// header
+	w.WriteHeader(SmgpQueryTERouteRespPktLen, seqId, SMGP_QUERY_TE_ROUTE_RESP)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteInt(binary.BigEndian, p.Status)
+	w.WriteFixedSizeString(p.QueryTermID, 21)
+	p.Route.Pack(w)
+
+	return w.Bytes()
+}
+
+func (p *SmgpQueryTERouteRespPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	r.ReadInt(binary.BigEndian, &p.Status)
+	p.QueryTermID = string(r.ReadCString(21))
+	p.Route.Unpack(r)
+
+	return r.Error()
+}
+
+func (p *SmgpQueryTERouteRespPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Query TE Route Resp ---")
+	fmt.Fprintln(&b, "Status: ", p.Status)
+	fmt.Fprintln(&b, "QueryTermID: ", p.QueryTermID)
+	fmt.Fprintln(&b, "Route: ", p.Route.String())
+	return b.String()
+}
+
+// 查询SP服务代码所属网关
+type SmgpQuerySPRouteReqPkt struct {
+	SrcGatewayID string // 源网关代码
+	SPCode       string // SP服务代码
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpQuerySPRouteReqPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpQuerySPRouteReqPktLen)
+	// header
+	w.WriteHeader(SmgpQuerySPRouteReqPktLen, seqId, SMGP_QUERY_SP_ROUTE)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteFixedSizeString(p.SrcGatewayID, 6)
+	w.WriteFixedSizeString(p.SPCode, 21)
+
+	return w.Bytes()
+}
+
+func (p *SmgpQuerySPRouteReqPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	p.SrcGatewayID = string(r.ReadCString(6))
+	p.SPCode = string(r.ReadCString(21))
+
+	return r.Error()
+}
+
+func (p *SmgpQuerySPRouteReqPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Query SP Route Req ---")
+	fmt.Fprintln(&b, "SrcGatewayID: ", p.SrcGatewayID)
+	fmt.Fprintln(&b, "SPCode: ", p.SPCode)
+	return b.String()
+}
+
+func (p *SmgpQuerySPRouteReqPkt) PrepareResponse(rsp Packer) {
+	if r, ok := rsp.(*SmgpQuerySPRouteRespPkt); ok {
+		r.SPCode = p.SPCode
+	}
+}
+
+type SmgpQuerySPRouteRespPkt struct {
+	Status Status
+	SPCode string
+	Route  SPRoute
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpQuerySPRouteRespPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpQuerySPRouteRespPktLen)
+	// header
+	w.WriteHeader(SmgpQuerySPRouteRespPktLen, seqId, SMGP_QUERY_SP_ROUTE_RESP)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteInt(binary.BigEndian, p.Status)
+	w.WriteFixedSizeString(p.SPCode, 21)
+	p.Route.Pack(w)
+
+	return w.Bytes()
+}
+
+func (p *SmgpQuerySPRouteRespPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	r.ReadInt(binary.BigEndian, &p.Status)
+	p.SPCode = string(r.ReadCString(21))
+	p.Route.Unpack(r)
+
+	return r.Error()
+}
+
+func (p *SmgpQuerySPRouteRespPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Query SP Route Resp ---")
+	fmt.Fprintln(&b, "Status: ", p.Status)
+	fmt.Fprintln(&b, "SPCode: ", p.SPCode)
+	fmt.Fprintln(&b, "Route: ", p.Route.String())
+	return b.String()
+}
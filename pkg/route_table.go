@@ -0,0 +1,206 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	SmgpGetAllTERouteReqPktLen = HeaderPktLen + 6 + 4 //22d, 0x16
+	SmgpGetAllSPRouteReqPktLen = HeaderPktLen + 6 + 4 //22d, 0x16
+
+	// 单个 GET_ALL 应答包最多可携带的路由记录数
+	MaxTERoutesPerPkt = (SMGP_PACKET_MAX - HeaderPktLen - 4 - 1) / TERoutePktLen //46
+	MaxSPRoutesPerPkt = (SMGP_PACKET_MAX - HeaderPktLen - 4 - 1) / SPRoutePktLen //39
+)
+
+// 分页获取全部终端路由
+// LastRouteID 为上一页最后一条路由的编号，首次获取时填0
+type SmgpGetAllTERouteReqPkt struct {
+	SrcGatewayID string // 源网关代码
+	LastRouteID  uint32 // 上一条路由编号
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpGetAllTERouteReqPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpGetAllTERouteReqPktLen)
+	// header
+	w.WriteHeader(SmgpGetAllTERouteReqPktLen, seqId, SMGP_GET_ALL_TE_ROUTE)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteFixedSizeString(p.SrcGatewayID, 6)
+	w.WriteInt(binary.BigEndian, p.LastRouteID)
+
+	return w.Bytes()
+}
+
+func (p *SmgpGetAllTERouteReqPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	p.SrcGatewayID = string(r.ReadCString(6))
+	r.ReadInt(binary.BigEndian, &p.LastRouteID)
+
+	return r.Error()
+}
+
+func (p *SmgpGetAllTERouteReqPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Get All TE Route Req ---")
+	fmt.Fprintln(&b, "SrcGatewayID: ", p.SrcGatewayID)
+	fmt.Fprintln(&b, "LastRouteID: ", p.LastRouteID)
+	return b.String()
+}
+
+// 应答中不含路由记录时表示已获取完毕
+type SmgpGetAllTERouteRespPkt struct {
+	Status Status
+	Routes []TERoute
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpGetAllTERouteRespPkt) Pack(seqId uint32) ([]byte, error) {
+	if uint32(len(p.Routes)) > MaxTERoutesPerPkt {
+		return nil, NewOpError(ErrMethodParamsInvalid,
+			fmt.Sprintf("SmgpGetAllTERouteRespPkt.Pack: too many routes: %d", len(p.Routes)))
+	}
+
+	var pktLen = HeaderPktLen + 4 + 1 + uint32(len(p.Routes))*TERoutePktLen
+	var w = newPkgWriter(pktLen)
+	// header
+	w.WriteHeader(pktLen, seqId, SMGP_GET_ALL_TE_ROUTE_RESP)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteInt(binary.BigEndian, p.Status)
+	w.WriteByte(uint8(len(p.Routes)))
+	for i := range p.Routes {
+		p.Routes[i].Pack(w)
+	}
+
+	return w.Bytes()
+}
+
+func (p *SmgpGetAllTERouteRespPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	r.ReadInt(binary.BigEndian, &p.Status)
+	count := r.ReadByte()
+	p.Routes = make([]TERoute, count)
+	for i := range p.Routes {
+		p.Routes[i].Unpack(r)
+	}
+
+	return r.Error()
+}
+
+func (p *SmgpGetAllTERouteRespPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Get All TE Route Resp ---")
+	fmt.Fprintln(&b, "Status: ", p.Status)
+	fmt.Fprintln(&b, "RouteCount: ", len(p.Routes))
+	for i := range p.Routes {
+		fmt.Fprintln(&b, "Route: ", p.Routes[i].String())
+	}
+	return b.String()
+}
+
+// 分页获取全部SP路由
+// LastRouteID 为上一页最后一条路由的编号，首次获取时填0
+type SmgpGetAllSPRouteReqPkt struct {
+	SrcGatewayID string // 源网关代码
+	LastRouteID  uint32 // 上一条路由编号
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpGetAllSPRouteReqPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpGetAllSPRouteReqPktLen)
+	// header
+	w.WriteHeader(SmgpGetAllSPRouteReqPktLen, seqId, SMGP_GET_ALL_SP_ROUTE)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteFixedSizeString(p.SrcGatewayID, 6)
+	w.WriteInt(binary.BigEndian, p.LastRouteID)
+
+	return w.Bytes()
+}
+
+func (p *SmgpGetAllSPRouteReqPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	p.SrcGatewayID = string(r.ReadCString(6))
+	r.ReadInt(binary.BigEndian, &p.LastRouteID)
+
+	return r.Error()
+}
+
+func (p *SmgpGetAllSPRouteReqPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Get All SP Route Req ---")
+	fmt.Fprintln(&b, "SrcGatewayID: ", p.SrcGatewayID)
+	fmt.Fprintln(&b, "LastRouteID: ", p.LastRouteID)
+	return b.String()
+}
+
+// 应答中不含路由记录时表示已获取完毕
+type SmgpGetAllSPRouteRespPkt struct {
+	Status Status
+	Routes []SPRoute
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpGetAllSPRouteRespPkt) Pack(seqId uint32) ([]byte, error) {
+	if uint32(len(p.Routes)) > MaxSPRoutesPerPkt {
+		return nil, NewOpError(ErrMethodParamsInvalid,
+			fmt.Sprintf("SmgpGetAllSPRouteRespPkt.Pack: too many routes: %d", len(p.Routes)))
+	}
+
+	var pktLen = HeaderPktLen + 4 + 1 + uint32(len(p.Routes))*SPRoutePktLen
+	var w = newPkgWriter(pktLen)
+	// header
+	w.WriteHeader(pktLen, seqId, SMGP_GET_ALL_SP_ROUTE_RESP)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteInt(binary.BigEndian, p.Status)
+	w.WriteByte(uint8(len(p.Routes)))
+	for i := range p.Routes {
+		p.Routes[i].Pack(w)
+	}
+
+	return w.Bytes()
+}
+
+func (p *SmgpGetAllSPRouteRespPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	r.ReadInt(binary.BigEndian, &p.Status)
+	count := r.ReadByte()
+	p.Routes = make([]SPRoute, count)
+	for i := range p.Routes {
+		p.Routes[i].Unpack(r)
+	}
+
+	return r.Error()
+}
+
+func (p *SmgpGetAllSPRouteRespPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Get All SP Route Resp ---")
+	fmt.Fprintln(&b, "Status: ", p.Status)
+	fmt.Fprintln(&b, "RouteCount: ", len(p.Routes))
+	for i := range p.Routes {
+		fmt.Fprintln(&b, "Route: ", p.Routes[i].String())
+	}
+	return b.String()
+}
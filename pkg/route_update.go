@@ -0,0 +1,357 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// 路由更新类型 UpdateType
+const (
+	ROUTE_ADD    = 0 // 添加
+	ROUTE_DELETE = 1 // 删除
+	ROUTE_MODIFY = 2 // 修改
+)
+
+const (
+	SmgpUpdateTERouteReqPktLen      = HeaderPktLen + 1 + TERoutePktLen //66d, 0x42
+	SmgpUpdateTERouteRespPktLen     = HeaderPktLen + 4 + 4             //20d, 0x14
+	SmgpUpdateSPRouteReqPktLen      = HeaderPktLen + 1 + SPRoutePktLen //75d, 0x4b
+	SmgpUpdateSPRouteRespPktLen     = HeaderPktLen + 4 + 4             //20d, 0x14
+	SmgpPushUpdateTERouteReqPktLen  = HeaderPktLen + 1 + TERoutePktLen //66d, 0x42
+	SmgpPushUpdateTERouteRespPktLen = HeaderPktLen + 4 + 4             //20d, 0x14
+	SmgpPushUpdateSPRouteReqPktLen  = HeaderPktLen + 1 + SPRoutePktLen //75d, 0x4b
+	SmgpPushUpdateSPRouteRespPktLen = HeaderPktLen + 4 + 4             //20d, 0x14
+)
+
+// 客户端请求网关更新终端路由
+type SmgpUpdateTERouteReqPkt struct {
+	UpdateType uint8 // 更新类型
+	Route      TERoute
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpUpdateTERouteReqPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpUpdateTERouteReqPktLen)
+	// header
+	w.WriteHeader(SmgpUpdateTERouteReqPktLen, seqId, SMGP_UPDATE_TE_ROUTE)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteByte(p.UpdateType)
+	p.Route.Pack(w)
+
+	return w.Bytes()
+}
+
+func (p *SmgpUpdateTERouteReqPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	p.UpdateType = r.ReadByte()
+	p.Route.Unpack(r)
+
+	return r.Error()
+}
+
+func (p *SmgpUpdateTERouteReqPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Update TE Route Req ---")
+	fmt.Fprintln(&b, "UpdateType: ", p.UpdateType)
+	fmt.Fprintln(&b, "Route: ", p.Route.String())
+	return b.String()
+}
+
+func (p *SmgpUpdateTERouteReqPkt) PrepareResponse(rsp Packer) {
+	if r, ok := rsp.(*SmgpUpdateTERouteRespPkt); ok {
+		r.RouteID = p.Route.RouteID
+	}
+}
+
+type SmgpUpdateTERouteRespPkt struct {
+	Status  Status
+	RouteID uint32
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpUpdateTERouteRespPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpUpdateTERouteRespPktLen)
+	// header
+	w.WriteHeader(SmgpUpdateTERouteRespPktLen, seqId, SMGP_UPDATE_TE_ROUTE_RESP)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteInt(binary.BigEndian, p.Status)
+	w.WriteInt(binary.BigEndian, p.RouteID)
+
+	return w.Bytes()
+}
+
+func (p *SmgpUpdateTERouteRespPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	r.ReadInt(binary.BigEndian, &p.Status)
+	r.ReadInt(binary.BigEndian, &p.RouteID)
+
+	return r.Error()
+}
+
+func (p *SmgpUpdateTERouteRespPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Update TE Route Resp ---")
+	fmt.Fprintln(&b, "Status: ", p.Status)
+	fmt.Fprintln(&b, "RouteID: ", p.RouteID)
+	return b.String()
+}
+
+// 客户端请求网关更新SP路由
+type SmgpUpdateSPRouteReqPkt struct {
+	UpdateType uint8 // 更新类型
+	Route      SPRoute
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpUpdateSPRouteReqPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpUpdateSPRouteReqPktLen)
+	// header
+	w.WriteHeader(SmgpUpdateSPRouteReqPktLen, seqId, SMGP_UPDATE_SP_ROUTE)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteByte(p.UpdateType)
+	p.Route.Pack(w)
+
+	return w.Bytes()
+}
+
+func (p *SmgpUpdateSPRouteReqPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	p.UpdateType = r.ReadByte()
+	p.Route.Unpack(r)
+
+	return r.Error()
+}
+
+func (p *SmgpUpdateSPRouteReqPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Update SP Route Req ---")
+	fmt.Fprintln(&b, "UpdateType: ", p.UpdateType)
+	fmt.Fprintln(&b, "Route: ", p.Route.String())
+	return b.String()
+}
+
+func (p *SmgpUpdateSPRouteReqPkt) PrepareResponse(rsp Packer) {
+	if r, ok := rsp.(*SmgpUpdateSPRouteRespPkt); ok {
+		r.RouteID = p.Route.RouteID
+	}
+}
+
+type SmgpUpdateSPRouteRespPkt struct {
+	Status  Status
+	RouteID uint32
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpUpdateSPRouteRespPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpUpdateSPRouteRespPktLen)
+	// header
+	w.WriteHeader(SmgpUpdateSPRouteRespPktLen, seqId, SMGP_UPDATE_SP_ROUTE_RESP)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteInt(binary.BigEndian, p.Status)
+	w.WriteInt(binary.BigEndian, p.RouteID)
+
+	return w.Bytes()
+}
+
+func (p *SmgpUpdateSPRouteRespPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	r.ReadInt(binary.BigEndian, &p.Status)
+	r.ReadInt(binary.BigEndian, &p.RouteID)
+
+	return r.Error()
+}
+
+func (p *SmgpUpdateSPRouteRespPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Update SP Route Resp ---")
+	fmt.Fprintln(&b, "Status: ", p.Status)
+	fmt.Fprintln(&b, "RouteID: ", p.RouteID)
+	return b.String()
+}
+
+// 网关主动向客户端推送终端路由更新
+type SmgpPushUpdateTERouteReqPkt struct {
+	UpdateType uint8 // 更新类型
+	Route      TERoute
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpPushUpdateTERouteReqPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpPushUpdateTERouteReqPktLen)
+	// header
+	w.WriteHeader(SmgpPushUpdateTERouteReqPktLen, seqId, SMGP_PUSH_UPDATE_TE_ROUTE)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteByte(p.UpdateType)
+	p.Route.Pack(w)
+
+	return w.Bytes()
+}
+
+func (p *SmgpPushUpdateTERouteReqPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	p.UpdateType = r.ReadByte()
+	p.Route.Unpack(r)
+
+	return r.Error()
+}
+
+func (p *SmgpPushUpdateTERouteReqPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Push Update TE Route Req ---")
+	fmt.Fprintln(&b, "UpdateType: ", p.UpdateType)
+	fmt.Fprintln(&b, "Route: ", p.Route.String())
+	return b.String()
+}
+
+func (p *SmgpPushUpdateTERouteReqPkt) PrepareResponse(rsp Packer) {
+	if r, ok := rsp.(*SmgpPushUpdateTERouteRespPkt); ok {
+		r.RouteID = p.Route.RouteID
+	}
+}
+
+type SmgpPushUpdateTERouteRespPkt struct {
+	Status  Status
+	RouteID uint32
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpPushUpdateTERouteRespPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpPushUpdateTERouteRespPktLen)
+	// header
+	w.WriteHeader(SmgpPushUpdateTERouteRespPktLen, seqId, SMGP_PUSH_UPDATE_TE_ROUTE_RESP)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteInt(binary.BigEndian, p.Status)
+	w.WriteInt(binary.BigEndian, p.RouteID)
+
+	return w.Bytes()
+}
+
+func (p *SmgpPushUpdateTERouteRespPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	r.ReadInt(binary.BigEndian, &p.Status)
+	r.ReadInt(binary.BigEndian, &p.RouteID)
+
+	return r.Error()
+}
+
+func (p *SmgpPushUpdateTERouteRespPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Push Update TE Route Resp ---")
+	fmt.Fprintln(&b, "Status: ", p.Status)
+	fmt.Fprintln(&b, "RouteID: ", p.RouteID)
+	return b.String()
+}
+
+// 网关主动向客户端推送SP路由更新
+type SmgpPushUpdateSPRouteReqPkt struct {
+	UpdateType uint8 // 更新类型
+	Route      SPRoute
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpPushUpdateSPRouteReqPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpPushUpdateSPRouteReqPktLen)
+	// header
+	w.WriteHeader(SmgpPushUpdateSPRouteReqPktLen, seqId, SMGP_PUSH_UPDATE_SP_ROUTE)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteByte(p.UpdateType)
+	p.Route.Pack(w)
+
+	return w.Bytes()
+}
+
+func (p *SmgpPushUpdateSPRouteReqPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	p.UpdateType = r.ReadByte()
+	p.Route.Unpack(r)
+
+	return r.Error()
+}
+
+func (p *SmgpPushUpdateSPRouteReqPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Push Update SP Route Req ---")
+	fmt.Fprintln(&b, "UpdateType: ", p.UpdateType)
+	fmt.Fprintln(&b, "Route: ", p.Route.String())
+	return b.String()
+}
+
+func (p *SmgpPushUpdateSPRouteReqPkt) PrepareResponse(rsp Packer) {
+	if r, ok := rsp.(*SmgpPushUpdateSPRouteRespPkt); ok {
+		r.RouteID = p.Route.RouteID
+	}
+}
+
+type SmgpPushUpdateSPRouteRespPkt struct {
+	Status  Status
+	RouteID uint32
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpPushUpdateSPRouteRespPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpPushUpdateSPRouteRespPktLen)
+	// header
+	w.WriteHeader(SmgpPushUpdateSPRouteRespPktLen, seqId, SMGP_PUSH_UPDATE_SP_ROUTE_RESP)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteInt(binary.BigEndian, p.Status)
+	w.WriteInt(binary.BigEndian, p.RouteID)
+
+	return w.Bytes()
+}
+
+func (p *SmgpPushUpdateSPRouteRespPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	r.ReadInt(binary.BigEndian, &p.Status)
+	r.ReadInt(binary.BigEndian, &p.RouteID)
+
+	return r.Error()
+}
+
+func (p *SmgpPushUpdateSPRouteRespPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Push Update SP Route Resp ---")
+	fmt.Fprintln(&b, "Status: ", p.Status)
+	fmt.Fprintln(&b, "RouteID: ", p.RouteID)
+	return b.String()
+}
@@ -0,0 +1,201 @@
+package pkg
+
+import (
+	"errors"
+	"math/rand"
+	"sync/atomic"
+
+	"github.com/boxtsecond/gosmgp/pkg/udh"
+)
+
+var (
+	ErrSplitEncoding      = errors.New("splitter: content is not valid for msg format")
+	ErrTooManySegments    = errors.New("splitter: too many segments")
+	ErrSplitFormatInvalid = errors.New("splitter: unsupported msg format")
+)
+
+const (
+	MaxSmsLength     = 140 // 单条短消息最大字节数
+	MaxSegmentsCount = 255 // 长短信最大分段数
+)
+
+// 长短信参考号分配器，可并发使用，每个连接各自持有一个
+type RefAllocator struct {
+	n uint32
+}
+
+func NewRefAllocator() *RefAllocator {
+	return &RefAllocator{n: rand.Uint32()}
+}
+
+// 8位参考号
+func (a *RefAllocator) Next8() uint8 {
+	return uint8(atomic.AddUint32(&a.n, 1))
+}
+
+// 16位参考号
+func (a *RefAllocator) Next16() uint16 {
+	return uint16(atomic.AddUint32(&a.n, 1))
+}
+
+// 未指定分配器时使用
+var defaultRefAllocator = NewRefAllocator()
+
+// 按字符切分长短信，不会把一个字符拆到两段中：
+// UCS2 不拆分代理对，GB18030 不拆分双字节与四字节字符。
+// 分段或指定了 IEs 时每段带上 UDH，需同时设置 TP_udhi=1。
+type Splitter struct {
+	MsgFormat uint8         // ASCII、BINARY、UCS2 或 GB18030，content 须已按此编码
+	Ref16     bool          // 使用16位参考号(IEI 0x08)，否则为8位(IEI 0x00)
+	Refs      *RefAllocator // 参考号分配器，为空时使用包内默认分配器
+	IEs       udh.Header    // 每段都带的其它信息元素，如应用端口
+}
+
+func NewSplitter(msgFormat uint8, refs *RefAllocator) *Splitter {
+	return &Splitter{MsgFormat: msgFormat, Refs: refs}
+}
+
+// 每段 UDH 的长度，含 UDHL 字节
+func (s *Splitter) udhLen(concat bool) int {
+	n := s.IEs.Len()
+	if !concat {
+		return n
+	}
+	if n == 0 {
+		n = 1
+	}
+	if s.Ref16 {
+		return n + 6
+	}
+	return n + 5
+}
+
+// 每段内容不含 UDH 的最大字节数
+func (s *Splitter) segmentLen(concat bool) int {
+	n := MaxSmsLength - s.udhLen(concat)
+	if s.MsgFormat == UCS2 {
+		n &^= 1
+	}
+	return n
+}
+
+// 返回 content 中第一个字符所占的字节数
+func (s *Splitter) charLen(b []byte) (int, error) {
+	switch s.MsgFormat {
+	case ASCII, BINARY, WRITE_CARD:
+		return 1, nil
+
+	case UCS2:
+		if len(b) < 2 {
+			return 0, ErrSplitEncoding
+		}
+		if b[0] >= 0xd8 && b[0] <= 0xdb && len(b) >= 4 && b[2] >= 0xdc && b[2] <= 0xdf {
+			return 4, nil
+		}
+		return 2, nil
+
+	case GB18030:
+		if b[0] < 0x81 || b[0] == 0xff {
+			return 1, nil
+		}
+		n := 2
+		if len(b) >= 2 && b[1] >= 0x30 && b[1] <= 0x39 {
+			n = 4
+		}
+		if len(b) < n {
+			return 0, ErrSplitEncoding
+		}
+		return n, nil
+	}
+	return 0, ErrSplitFormatInvalid
+}
+
+// 计算每段的结束位置，同时校验编码
+func (s *Splitter) boundaries(content []byte) ([]int, error) {
+	switch s.MsgFormat {
+	case ASCII, BINARY, WRITE_CARD, UCS2, GB18030:
+	default:
+		return nil, ErrSplitFormatInvalid
+	}
+
+	max := s.segmentLen(false)
+	if len(content) > max {
+		max = s.segmentLen(true)
+	}
+	if max <= 0 {
+		return nil, udh.ErrIETooLong
+	}
+
+	var ends []int
+	start, pos := 0, 0
+	for pos < len(content) {
+		n, err := s.charLen(content[pos:])
+		if err != nil {
+			return nil, err
+		}
+		if pos+n-start > max {
+			ends = append(ends, pos)
+			start = pos
+		}
+		pos += n
+	}
+	ends = append(ends, pos)
+
+	if len(ends) > MaxSegmentsCount {
+		return nil, ErrTooManySegments
+	}
+	return ends, nil
+}
+
+// 分段数，不占用参考号
+func (s *Splitter) Count(content []byte) (int, error) {
+	ends, err := s.boundaries(content)
+	if err != nil {
+		return 0, err
+	}
+	return len(ends), nil
+}
+
+// 切分 content，只有一段且未指定 IEs 时原样返回，不带 UDH
+func (s *Splitter) Split(content []byte) ([][]byte, error) {
+	ends, err := s.boundaries(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(ends) == 1 {
+		header, err := s.IEs.Bytes()
+		if err != nil || header == nil {
+			return [][]byte{content}, err
+		}
+		return [][]byte{append(header, content...)}, nil
+	}
+
+	refs := s.Refs
+	if refs == nil {
+		refs = defaultRefAllocator
+	}
+	var ref uint16
+	if s.Ref16 {
+		ref = refs.Next16()
+	} else {
+		ref = uint16(refs.Next8())
+	}
+
+	chunks := make([][]byte, 0, len(ends))
+	start := 0
+	for i, end := range ends {
+		h := append(udh.Header{}, s.IEs...)
+		if s.Ref16 {
+			h = append(h, udh.Concat16(ref, byte(len(ends)), byte(i+1)))
+		} else {
+			h = append(h, udh.Concat8(uint8(ref), byte(len(ends)), byte(i+1)))
+		}
+		header, err := h.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, append(header, content[start:end]...))
+		start = end
+	}
+	return chunks, nil
+}
@@ -0,0 +1,162 @@
+package pkg
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/boxtsecond/gosmgp/pkg/udh"
+)
+
+func joinBytes(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+var (
+	ucs2Han       = []byte{0x4e, 0x2d}             // 中
+	ucs2Surrogate = []byte{0xd8, 0x3d, 0xde, 0x00} // U+1F600
+	gbHan         = []byte{0xd6, 0xd0}             // 中
+	gbFour        = []byte{0x81, 0x30, 0x81, 0x30} // U+0080
+)
+
+func TestSplitterBoundaries(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  uint8
+		ref16   bool
+		content []byte
+		bodies  []int // 每段去掉 UDH 后的字节数
+	}{
+		{"ascii single 140", ASCII, false, bytes.Repeat([]byte("a"), 140), []int{140}},
+		{"ascii 141", ASCII, false, bytes.Repeat([]byte("a"), 141), []int{134, 7}},
+		{"ascii 141 ref16", ASCII, true, bytes.Repeat([]byte("a"), 141), []int{133, 8}},
+
+		{"ucs2 single 140", UCS2, false, bytes.Repeat(ucs2Han, 70), []int{140}},
+		{"ucs2 142", UCS2, false, bytes.Repeat(ucs2Han, 71), []int{134, 8}},
+		// 8位参考号时每段134字节，代理对位于 132-136，整体移到下一段
+		{"ucs2 surrogate straddles 134", UCS2, false,
+			joinBytes(bytes.Repeat(ucs2Han, 66), ucs2Surrogate, bytes.Repeat(ucs2Han, 4)), []int{132, 12}},
+		{"ucs2 surrogate ends at 134", UCS2, false,
+			joinBytes(bytes.Repeat(ucs2Han, 65), ucs2Surrogate, bytes.Repeat(ucs2Han, 4)), []int{134, 8}},
+		// 16位参考号时每段133字节，UCS2 取偶数132
+		{"ucs2 surrogate straddles 132 ref16", UCS2, true,
+			joinBytes(bytes.Repeat(ucs2Han, 65), ucs2Surrogate, bytes.Repeat(ucs2Han, 4)), []int{130, 12}},
+
+		{"gb18030 four-byte straddles 134", GB18030, false,
+			joinBytes(bytes.Repeat([]byte("a"), 132), gbFour, []byte("bcdefghijk")), []int{132, 14}},
+		{"gb18030 four-byte ends at 134", GB18030, false,
+			joinBytes(bytes.Repeat([]byte("a"), 130), gbFour, []byte("bcdefghijk")), []int{134, 10}},
+		{"gb18030 two-byte straddles 134", GB18030, false,
+			joinBytes(bytes.Repeat([]byte("a"), 133), gbHan, []byte("bcdefghijk")), []int{133, 12}},
+		{"gb18030 four-byte straddles 133 ref16", GB18030, true,
+			joinBytes(bytes.Repeat([]byte("a"), 131), gbFour, []byte("bcdefghijk")), []int{131, 14}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Splitter{MsgFormat: tt.format, Ref16: tt.ref16, Refs: NewRefAllocator()}
+			if n, err := s.Count(tt.content); err != nil || n != len(tt.bodies) {
+				t.Fatalf("Count = %d, %v, want %d", n, err, len(tt.bodies))
+			}
+			chunks, err := s.Split(tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(chunks) != len(tt.bodies) {
+				t.Fatalf("got %d segments, want %d", len(chunks), len(tt.bodies))
+			}
+
+			var joined []byte
+			var ref uint16
+			for i, chunk := range chunks {
+				if len(chunk) > MaxSmsLength {
+					t.Errorf("segment %d is %d bytes", i+1, len(chunk))
+				}
+				body := chunk
+				if len(chunks) > 1 {
+					h, b, err := udh.Parse(chunk)
+					if err != nil {
+						t.Fatalf("segment %d: %v", i+1, err)
+					}
+					c, ok := h.Concat()
+					if !ok || c.Ref16 != tt.ref16 || int(c.Total) != len(chunks) || int(c.Seq) != i+1 {
+						t.Fatalf("segment %d concat = %+v, %v", i+1, c, ok)
+					}
+					if i == 0 {
+						ref = c.Ref
+					} else if c.Ref != ref {
+						t.Errorf("segment %d ref = %d, want %d", i+1, c.Ref, ref)
+					}
+					body = b
+				}
+				if len(body) != tt.bodies[i] {
+					t.Errorf("segment %d body = %d bytes, want %d", i+1, len(body), tt.bodies[i])
+				}
+				joined = append(joined, body...)
+			}
+			if !bytes.Equal(joined, tt.content) {
+				t.Error("joined segments differ from the content")
+			}
+		})
+	}
+}
+
+func TestSplitterInvalidEncoding(t *testing.T) {
+	tests := []struct {
+		format  uint8
+		content []byte
+		err     error
+	}{
+		{UCS2, []byte{0x4e}, ErrSplitEncoding},
+		{UCS2, joinBytes(bytes.Repeat(ucs2Han, 80), []byte{0x4e}), ErrSplitEncoding},
+		{GB18030, []byte{0xd6}, ErrSplitEncoding},
+		{GB18030, []byte{0x81, 0x30, 0x81}, ErrSplitEncoding},
+		{0xff, []byte("a"), ErrSplitFormatInvalid},
+	}
+	for _, tt := range tests {
+		if _, err := NewSplitter(tt.format, nil).Split(tt.content); err != tt.err {
+			t.Errorf("Split(%d, % x) error = %v, want %v", tt.format, tt.content, err, tt.err)
+		}
+	}
+}
+
+func TestRefAllocatorConcurrent(t *testing.T) {
+	const workers, each = 8, 256
+
+	// 并发取 workers*each 个连续的参考号：16位参考号不重复，8位参考号每个值恰好出现 workers 次
+	refs8 := make(map[uint8]int)
+	refs16 := make(map[uint16]int)
+	a8, a16 := NewRefAllocator(), NewRefAllocator()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var got8 [each]uint8
+			var got16 [each]uint16
+			for i := 0; i < each; i++ {
+				got8[i], got16[i] = a8.Next8(), a16.Next16()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for i := 0; i < each; i++ {
+				refs8[got8[i]]++
+				refs16[got16[i]]++
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(refs16) != workers*each {
+		t.Errorf("got %d distinct 16-bit refs, want %d", len(refs16), workers*each)
+	}
+	if len(refs8) != 256 {
+		t.Errorf("got %d distinct 8-bit refs, want 256", len(refs8))
+	}
+	for r, n := range refs8 {
+		if n != workers {
+			t.Errorf("8-bit ref %d used %d times, want %d", r, n, workers)
+		}
+	}
+}
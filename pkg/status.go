@@ -128,5 +128,33 @@ func (s Status) String() string {
 }
 
 const (
-	STAT_OK Status = iota
+	STAT_OK                 Status = 0  // 成功
+	STAT_SYS_BUSY           Status = 1  // 系统忙
+	STAT_MAX_CONN_EXCEED    Status = 2  // 超过最大连接数
+	STAT_MSG_STRUCT_ERR     Status = 10 // 消息结构错
+	STAT_COMMAND_ERR        Status = 11 // 命令字错
+	STAT_SEQ_DUPLICATE      Status = 12 // 序列号重复
+	STAT_IP_ERR             Status = 20 // IP地址错
+	STAT_AUTH_ERR           Status = 21 // 认证错
+	STAT_VERSION_TOO_HIGH   Status = 22 // 版本太高
+	STAT_MSG_TYPE_ERR       Status = 30 // 非法消息类型（MsgType）
+	STAT_PRIORITY_ERR       Status = 31 // 非法优先级（Priority）
+	STAT_FEE_TYPE_ERR       Status = 32 // 非法资费类型（FeeType）
+	STAT_FEE_CODE_ERR       Status = 33 // 非法资费代码（FeeCode）
+	STAT_MSG_FORMAT_ERR     Status = 34 // 非法短消息格式（MsgFormat）
+	STAT_TIME_FORMAT_ERR    Status = 35 // 非法时间格式
+	STAT_MSG_LENGTH_ERR     Status = 36 // 非法短消息长度（MsgLength）
+	STAT_EXPIRED            Status = 37 // 有效期已过
+	STAT_QUERY_TYPE_ERR     Status = 38 // 非法查询类别（QueryType）
+	STAT_ROUTE_ERR          Status = 39 // 路由错误
+	STAT_FIXED_FEE_ERR      Status = 40 // 非法包月费/封顶费（FixedFee）
+	STAT_UPDATE_TYPE_ERR    Status = 41 // 非法更新类型（UpdateType）
+	STAT_ROUTE_ID_ERR       Status = 42 // 非法路由编号（RouteId）
+	STAT_SERVICE_ID_ERR     Status = 43 // 非法服务代码（ServiceId）
+	STAT_VALID_TIME_ERR     Status = 44 // 非法有效期（ValidTime）
+	STAT_AT_TIME_ERR        Status = 45 // 非法定时发送时间（AtTime）
+	STAT_SRC_TERM_ID_ERR    Status = 46 // 非法发送用户号码（SrcTermId）
+	STAT_DEST_TERM_ID_ERR   Status = 47 // 非法接收用户号码（DestTermId）
+	STAT_CHARGE_TERM_ID_ERR Status = 48 // 非法计费用户号码（ChargeTermId）
+	STAT_SP_CODE_ERR        Status = 49 // 非法SP服务代码（SPCode）
 )
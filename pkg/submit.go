@@ -3,7 +3,6 @@ package pkg
 import (
 	"bytes"
 	"encoding/binary"
-	"encoding/hex"
 	"fmt"
 )
 
@@ -20,18 +19,20 @@ type SmgpSubmitReqPkt struct {
 	FeeCode         string   // 资费代码
 	FixedFee        string   // 包月费/封顶费
 	MsgFormat       uint8    // 短消息格式
-	ValidTime       string   // 短消息有效时间
-	AtTime          string   // 短消息定时发送时间
+	ValidTime       SmgpTime // 短消息有效时间
+	AtTime          SmgpTime // 短消息定时发送时间
 	SrcTermID       string   // 短信息发送方号码
 	ChargeTermID    string   // 计费用户号码
 	DestTermIDCount uint8    // 短消息接收号码总数，最多 100
 	DestTermID      []string // 短消息接收号码
 	MsgLength       uint8    // 短消息长度
-	MsgContent      string   // 短消息内容
+	MsgContent      []byte   // 短消息内容
 	Reserve         string   // 保留
 
 	// 可选参数
 	Options Options
+	// 解析时按原始顺序保留的全部可选参数，含重复的 Tag，仅供查看
+	OptionList []*TLV
 
 	// used in session
 	SequenceID uint32
@@ -53,8 +54,8 @@ func (p *SmgpSubmitReqPkt) Pack(seqId uint32) ([]byte, error) {
 	w.WriteFixedSizeString(p.FeeCode, 6)
 	w.WriteFixedSizeString(p.FixedFee, 6)
 	w.WriteByte(p.MsgFormat)
-	w.WriteFixedSizeString(p.ValidTime, 17)
-	w.WriteFixedSizeString(p.AtTime, 17)
+	w.WriteFixedSizeString(string(p.ValidTime), 17)
+	w.WriteFixedSizeString(string(p.AtTime), 17)
 	w.WriteFixedSizeString(p.SrcTermID, 21)
 	w.WriteFixedSizeString(p.ChargeTermID, 21)
 	w.WriteByte(p.DestTermIDCount)
@@ -63,13 +64,10 @@ func (p *SmgpSubmitReqPkt) Pack(seqId uint32) ([]byte, error) {
 		w.WriteFixedSizeString(d, 21)
 	}
 	w.WriteByte(p.MsgLength)
-	w.WriteString(p.MsgContent)
+	w.WriteBytes(p.MsgContent)
 	w.WriteFixedSizeString(p.Reserve, 8)
 
-	for _, o := range p.Options {
-		b, _ := o.Byte()
-		w.WriteBytes(b)
-	}
+	p.Options.Pack(w)
 
 	return w.Bytes()
 }
@@ -86,8 +84,8 @@ func (p *SmgpSubmitReqPkt) Unpack(data []byte) error {
 	p.FeeCode = string(r.ReadCString(6))
 	p.FixedFee = string(r.ReadCString(6))
 	p.MsgFormat = r.ReadByte()
-	p.ValidTime = string(r.ReadCString(17))
-	p.AtTime = string(r.ReadCString(17))
+	p.ValidTime = SmgpTime(r.ReadCString(17))
+	p.AtTime = SmgpTime(r.ReadCString(17))
 	p.SrcTermID = string(r.ReadCString(21))
 	p.ChargeTermID = string(r.ReadCString(21))
 	p.DestTermIDCount = r.ReadByte()
@@ -99,17 +97,22 @@ func (p *SmgpSubmitReqPkt) Unpack(data []byte) error {
 	p.MsgLength = r.ReadByte()
 	msgContent := make([]byte, p.MsgLength)
 	r.ReadBytes(msgContent)
-	p.MsgContent = string(msgContent)
+	p.MsgContent = msgContent
 	p.Reserve = string(r.ReadCString(8))
 	offset += 1 + int(p.MsgLength) + 8
 
-	options, err := ParseOptions(data[offset:])
+	if err := r.Error(); err != nil {
+		return err
+	}
+
+	optionList, err := ParseOptionList(data[offset:])
 	if err != nil {
 		return err
 	}
-	p.Options = options
+	p.OptionList = optionList
+	p.Options = NewOptions(optionList...)
 
-	return r.Error()
+	return nil
 }
 
 func (p *SmgpSubmitReqPkt) String() string {
@@ -136,14 +139,14 @@ func (p *SmgpSubmitReqPkt) String() string {
 	}
 
 	fmt.Fprintln(&b, "MsgLength: ", p.MsgLength)
-	fmt.Fprintln(&b, "MsgContent: ", p.MsgContent)
+	fmt.Fprintln(&b, "MsgContent: ", string(p.MsgContent))
 	fmt.Fprintln(&b, "Options: ", p.Options.String())
 
 	return b.String()
 }
 
 type SmgpSubmitRespPkt struct {
-	MsgID  string
+	MsgID  MsgID
 	Status Status
 
 	// used in session
@@ -157,8 +160,7 @@ func (p *SmgpSubmitRespPkt) Pack(seqId uint32) ([]byte, error) {
 	p.SequenceID = seqId
 
 	// body
-	msgId, _ := hex.DecodeString(p.MsgID)
-	w.WriteBytes(NewOctetString(fmt.Sprintf("%s", msgId)).Byte(10))
+	w.WriteBytes(p.MsgID[:])
 	w.WriteInt(binary.BigEndian, p.Status)
 	return w.Bytes()
 }
@@ -167,9 +169,7 @@ func (p *SmgpSubmitRespPkt) Unpack(data []byte) error {
 	var r = newPkgReader(data)
 
 	// Body: MsgID
-	var s = make([]byte, 10)
-	r.ReadBytes(s)
-	p.MsgID = hex.EncodeToString(s)
+	r.ReadBytes(p.MsgID[:])
 	// Body: Status
 	r.ReadInt(binary.BigEndian, &p.Status)
 	return r.Error()
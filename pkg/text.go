@@ -0,0 +1,98 @@
+package pkg
+
+import "errors"
+
+var (
+	ErrNotTextFormat = errors.New("msg format is not a text format")
+	ErrTextTooLong   = errors.New("text is too long for one message")
+)
+
+// 按 MsgFormat 解码短消息内容，TP_udhi=1 时先去掉 UDH
+func decodeContent(format uint8, options Options, content []byte) (string, error) {
+	_, body, err := splitUDH(options, content)
+	if err != nil {
+		return "", err
+	}
+
+	switch format {
+	case ASCII:
+		return string(body), nil
+	case UCS2:
+		return Ucs2ToUtf8(string(body))
+	case GB18030:
+		return GB18030ToUtf8(string(body))
+	}
+	return "", ErrNotTextFormat
+}
+
+// 按 format 编码 text，TP_udhi=1 时保留原内容中的 UDH
+func encodeContent(text string, format uint8, options Options, content []byte) ([]byte, error) {
+	var policy EncodePolicy
+	switch format {
+	case ASCII:
+		policy = ENCODE_ASCII
+	case UCS2:
+		policy = ENCODE_UCS2
+	case GB18030:
+		policy = ENCODE_GB18030
+	default:
+		return nil, ErrNotTextFormat
+	}
+
+	_, encoded, err := encodeText(text, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	if udhi, _ := options.TPUdhi(); udhi == 1 {
+		h, _, err := splitUDH(options, content)
+		if err != nil {
+			return nil, err
+		}
+		header, err := h.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(header, encoded...)
+	}
+
+	if len(encoded) > MaxMsgLength {
+		return nil, ErrTextTooLong
+	}
+	return encoded, nil
+}
+
+// 按 MsgFormat 解码短消息内容为 UTF-8 文本，TP_udhi=1 时去掉 UDH
+func (p *SmgpSubmitReqPkt) Text() (string, error) {
+	return decodeContent(p.MsgFormat, p.Options, p.MsgContent)
+}
+
+// 按 format 编码 text 并同步 MsgFormat、MsgLength，TP_udhi=1 时保留原内容中的 UDH；
+// 超出单条长度时返回 ErrTextTooLong，长短信使用 EncodeText
+func (p *SmgpSubmitReqPkt) SetText(text string, format uint8) error {
+	content, err := encodeContent(text, format, p.Options, p.MsgContent)
+	if err != nil {
+		return err
+	}
+	p.MsgFormat = format
+	p.MsgContent = content
+	p.MsgLength = uint8(len(content))
+	return nil
+}
+
+// 按 MsgFormat 解码短消息内容为 UTF-8 文本，TP_udhi=1 时去掉 UDH
+func (p *SmgpDeliverReqPkt) Text() (string, error) {
+	return decodeContent(p.MsgFormat, p.Options, p.MsgContent)
+}
+
+// 按 format 编码 text 并同步 MsgFormat、MsgLength，TP_udhi=1 时保留原内容中的 UDH
+func (p *SmgpDeliverReqPkt) SetText(text string, format uint8) error {
+	content, err := encodeContent(text, format, p.Options, p.MsgContent)
+	if err != nil {
+		return err
+	}
+	p.MsgFormat = format
+	p.MsgContent = content
+	p.MsgLength = uint8(len(content))
+	return nil
+}
@@ -0,0 +1,135 @@
+package pkg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/boxtsecond/gosmgp/pkg/udh"
+)
+
+func TestSubmitText(t *testing.T) {
+	header := []byte{5, udh.IEI_CONCAT_8, 3, 9, 2, 1}
+	tests := []struct {
+		name   string
+		format uint8
+		udhi   bool
+		text   string
+	}{
+		{"ascii", ASCII, false, "hello"},
+		{"ucs2", UCS2, false, "你好😀"},
+		{"gb18030", GB18030, false, "你好😀"},
+		{"ascii udh", ASCII, true, "hello"},
+		{"ucs2 udh", UCS2, true, "你好"},
+		{"gb18030 udh", GB18030, true, "你好"},
+		{"empty udh", UCS2, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &SmgpSubmitReqPkt{MsgFormat: BINARY}
+			d := &SmgpDeliverReqPkt{MsgFormat: BINARY}
+			if tt.udhi {
+				p.Options.SetTPUdhi(1)
+				d.Options.SetTPUdhi(1)
+				p.MsgContent = append([]byte(nil), header...)
+				d.MsgContent = append([]byte(nil), header...)
+			}
+
+			if err := p.SetText(tt.text, tt.format); err != nil {
+				t.Fatal(err)
+			}
+			if err := d.SetText(tt.text, tt.format); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(p.MsgContent, d.MsgContent) {
+				t.Errorf("submit content % x, deliver content % x", p.MsgContent, d.MsgContent)
+			}
+			if p.MsgFormat != tt.format || int(p.MsgLength) != len(p.MsgContent) {
+				t.Errorf("MsgFormat %d, MsgLength %d for %d bytes", p.MsgFormat, p.MsgLength, len(p.MsgContent))
+			}
+			// 带 UDH 时重新编码后 UDH 原样保留在内容开头
+			if tt.udhi && !bytes.HasPrefix(p.MsgContent, header) {
+				t.Errorf("content % x lost the UDH % x", p.MsgContent, header)
+			}
+
+			if got, err := p.Text(); err != nil || got != tt.text {
+				t.Errorf("submit Text = %q, %v, want %q", got, err, tt.text)
+			}
+			if got, err := d.Text(); err != nil || got != tt.text {
+				t.Errorf("deliver Text = %q, %v, want %q", got, err, tt.text)
+			}
+		})
+	}
+}
+
+func TestSubmitTextReplace(t *testing.T) {
+	p := &SmgpSubmitReqPkt{}
+	p.Options.SetTPUdhi(1)
+	header := []byte{6, udh.IEI_PORT_16, 4, 0x15, 0x80, 0, 0}
+	p.MsgContent = append(append([]byte(nil), header...), "old text"...)
+	p.MsgFormat, p.MsgLength = ASCII, uint8(len(p.MsgContent))
+
+	if got, err := p.Text(); err != nil || got != "old text" {
+		t.Fatalf("Text = %q, %v, want %q", got, err, "old text")
+	}
+	// 改为 UCS2 后 MsgFormat、MsgLength 同步，UDH 不重复
+	if err := p.SetText("新", UCS2); err != nil {
+		t.Fatal(err)
+	}
+	want := append(append([]byte(nil), header...), 0x65, 0xb0)
+	if !bytes.Equal(p.MsgContent, want) || p.MsgFormat != UCS2 || p.MsgLength != uint8(len(want)) {
+		t.Errorf("SetText = %d % x (MsgLength %d), want %d % x", p.MsgFormat, p.MsgContent, p.MsgLength, UCS2, want)
+	}
+}
+
+func TestSubmitTextErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		udhi    bool
+		content []byte
+		text    string
+		format  uint8
+		err     error
+	}{
+		{"ascii 255", false, nil, strings.Repeat("a", 255), ASCII, nil},
+		{"ascii 256", false, nil, strings.Repeat("a", 256), ASCII, ErrTextTooLong},
+		{"ucs2 256", false, nil, strings.Repeat("中", 128), UCS2, ErrTextTooLong},
+		// UDH 6字节计入长度
+		{"udh 255", true, []byte{5, 0, 3, 1, 2, 1}, strings.Repeat("a", 249), ASCII, nil},
+		{"udh 256", true, []byte{5, 0, 3, 1, 2, 1}, strings.Repeat("a", 250), ASCII, ErrTextTooLong},
+		{"malformed udh", true, []byte{9, 0, 3}, "a", ASCII, udh.ErrInvalidUDH},
+		{"not ascii", false, nil, "你好", ASCII, ErrNotASCII},
+		{"binary", false, nil, "a", BINARY, ErrNotTextFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &SmgpSubmitReqPkt{MsgFormat: GB18030, MsgLength: uint8(len(tt.content)), MsgContent: tt.content}
+			if tt.udhi {
+				p.Options.SetTPUdhi(1)
+			}
+			err := p.SetText(tt.text, tt.format)
+			if err != tt.err {
+				t.Fatalf("SetText error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				// 出错时不修改
+				if p.MsgFormat != GB18030 || !bytes.Equal(p.MsgContent, tt.content) || int(p.MsgLength) != len(tt.content) {
+					t.Errorf("packet modified: %d % x (MsgLength %d)", p.MsgFormat, p.MsgContent, p.MsgLength)
+				}
+				return
+			}
+			if int(p.MsgLength) != len(p.MsgContent) || len(p.MsgContent) != MaxMsgLength {
+				t.Errorf("MsgLength %d for %d bytes, want %d", p.MsgLength, len(p.MsgContent), MaxMsgLength)
+			}
+		})
+	}
+
+	if _, err := (&SmgpSubmitReqPkt{MsgFormat: BINARY, MsgContent: []byte{1}}).Text(); err != ErrNotTextFormat {
+		t.Errorf("binary Text error = %v, want %v", err, ErrNotTextFormat)
+	}
+	d := &SmgpDeliverReqPkt{MsgFormat: ASCII, MsgContent: []byte{9, 0}}
+	d.Options.SetTPUdhi(1)
+	if _, err := d.Text(); err != udh.ErrInvalidUDH {
+		t.Errorf("malformed UDH Text error = %v, want %v", err, udh.ErrInvalidUDH)
+	}
+}
@@ -0,0 +1,182 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrSmgpTimeInvalid = errors.New("SmgpTime: invalid time format")
+
+// 短消息有效时间/定时发送时间，格式为 YYMMDDhhmmsstnnp，空串表示未设置。
+//
+//	YYMMDDhhmmss 年月日时分秒
+//	t  十分之一秒
+//	nn 与UTC相差的刻钟数（15分钟）
+//	p  '+' 表示早于UTC，'-' 表示晚于UTC，'R' 表示相对时间
+//
+// 相对时间中 YYMMDDhhmmss 为相对于当前时间的年月日时分秒，tnn 固定为000。
+type SmgpTime string
+
+const (
+	smgpTimeLen         = 16
+	smgpTimeMaxQuarters = 56 // nn 的最大值，覆盖 UTC-14 至 UTC+14
+)
+
+// 由绝对时间生成 SmgpTime，保留 t 所在时区。
+// 时区偏移不是15分钟整数倍或超出 ±14 小时时转换为UTC。
+func NewSmgpTime(t time.Time) SmgpTime {
+	_, offset := t.Zone()
+	if offset%900 != 0 || offset > smgpTimeMaxQuarters*900 || offset < -smgpTimeMaxQuarters*900 {
+		t = t.UTC()
+		offset = 0
+	}
+
+	p := byte('+')
+	if offset < 0 {
+		p = '-'
+		offset = -offset
+	}
+	tenth := t.Nanosecond() / int(100*time.Millisecond)
+	return SmgpTime(fmt.Sprintf("%s%d%02d%c", t.Format("060102150405"), tenth, offset/900, p))
+}
+
+// 由相对时长生成 SmgpTime，按 1年=365天、1月=30天 换算，不足1秒的部分舍去
+func NewRelativeSmgpTime(d time.Duration) SmgpTime {
+	if d < 0 {
+		d = 0
+	}
+	secs := int64(d / time.Second)
+	days := secs / 86400
+	secs %= 86400
+
+	years := days / 365
+	days %= 365
+	months := days / 30
+	days %= 30
+	if years > 99 {
+		years = 99
+	}
+
+	return SmgpTime(fmt.Sprintf("%02d%02d%02d%02d%02d%02d000R",
+		years, months, days, secs/3600, secs%3600/60, secs%60))
+}
+
+func (s SmgpTime) IsZero() bool {
+	return s == ""
+}
+
+func (s SmgpTime) IsRelative() bool {
+	return len(s) == smgpTimeLen && s[smgpTimeLen-1] == 'R'
+}
+
+// 解析出的各字段
+func (s SmgpTime) fields() (v [7]int, err error) {
+	if len(s) != smgpTimeLen {
+		return v, ErrSmgpTimeInvalid
+	}
+	str := string(s)
+	for i := 0; i < 6; i++ {
+		n, ok := digits(str[i*2 : i*2+2])
+		if !ok {
+			return v, ErrSmgpTimeInvalid
+		}
+		v[i] = n
+	}
+	n, ok := digits(str[12:13])
+	if !ok {
+		return v, ErrSmgpTimeInvalid
+	}
+	v[6] = n
+	return v, nil
+}
+
+// 解析全部由 ASCII 数字组成的十进制数，不接受符号
+func digits(str string) (int, bool) {
+	n := 0
+	for i := 0; i < len(str); i++ {
+		if str[i] < '0' || str[i] > '9' {
+			return 0, false
+		}
+		n = n*10 + int(str[i]-'0')
+	}
+	return n, true
+}
+
+// 返回绝对时间，时区为 nn、p 表示的固定时区
+func (s SmgpTime) Time() (time.Time, error) {
+	if s.IsRelative() {
+		return time.Time{}, NewOpError(ErrSmgpTimeInvalid, "SmgpTime.Time: relative time "+string(s))
+	}
+	v, err := s.fields()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	str := string(s)
+	q, ok := digits(str[13:15])
+	if !ok || q > smgpTimeMaxQuarters {
+		return time.Time{}, ErrSmgpTimeInvalid
+	}
+	offset := q * 900
+	switch str[15] {
+	case '+':
+	case '-':
+		offset = -offset
+	default:
+		return time.Time{}, ErrSmgpTimeInvalid
+	}
+
+	if v[1] < 1 || v[1] > 12 || v[3] > 23 || v[4] > 59 || v[5] > 59 {
+		return time.Time{}, ErrSmgpTimeInvalid
+	}
+	// 日不能超过当月天数，time.Date 会把 0231 规范化为 0303
+	if days := time.Date(2000+v[0], time.Month(v[1])+1, 0, 0, 0, 0, 0, time.UTC).Day(); v[2] < 1 || v[2] > days {
+		return time.Time{}, ErrSmgpTimeInvalid
+	}
+	loc := time.FixedZone("", offset)
+	return time.Date(2000+v[0], time.Month(v[1]), v[2], v[3], v[4], v[5],
+		v[6]*int(100*time.Millisecond), loc), nil
+}
+
+// 返回相对时长，按 1年=365天、1月=30天 换算
+func (s SmgpTime) Duration() (time.Duration, error) {
+	if !s.IsRelative() {
+		return 0, NewOpError(ErrSmgpTimeInvalid, "SmgpTime.Duration: absolute time "+string(s))
+	}
+	v, err := s.fields()
+	if err != nil {
+		return 0, err
+	}
+
+	days := v[0]*365 + v[1]*30 + v[2]
+	return time.Duration(days)*24*time.Hour +
+		time.Duration(v[3])*time.Hour +
+		time.Duration(v[4])*time.Minute +
+		time.Duration(v[5])*time.Second, nil
+}
+
+// 返回相对于 base 的绝对时间，绝对时间直接返回
+func (s SmgpTime) At(base time.Time) (time.Time, error) {
+	if s.IsRelative() {
+		d, err := s.Duration()
+		if err != nil {
+			return time.Time{}, err
+		}
+		return base.Add(d), nil
+	}
+	return s.Time()
+}
+
+// 校验格式，空串合法
+func (s SmgpTime) Validate() error {
+	if s.IsZero() {
+		return nil
+	}
+	if s.IsRelative() {
+		_, err := s.Duration()
+		return err
+	}
+	_, err := s.Time()
+	return err
+}
@@ -0,0 +1,86 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewSmgpTimeValidates(t *testing.T) {
+	base := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		offset int
+		want   SmgpTime
+	}{
+		{"UTC", 0, "260110090000000+"},
+		{"UTC+8", 8 * 3600, "260110170000032+"},
+		{"UTC+13", 13 * 3600, "260110220000052+"},
+		{"UTC+14", 14 * 3600, "260110230000056+"},
+		{"UTC-12", -12 * 3600, "260109210000048-"},
+		{"UTC+5:45", 5*3600 + 45*60, "260110144500023+"},
+		{"UTC+5:30", 5*3600 + 30*60, "260110143000022+"},
+		{"UTC+0:20", 20 * 60, "260110090000000+"},
+		{"UTC+15", 15 * 3600, "260110090000000+"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSmgpTime(base.In(time.FixedZone(tt.name, tt.offset)))
+			if s != tt.want {
+				t.Errorf("NewSmgpTime = %s, want %s", s, tt.want)
+			}
+			if err := s.Validate(); err != nil {
+				t.Fatalf("Validate(%s) = %v", s, err)
+			}
+			got, err := s.Time()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(base) {
+				t.Errorf("Time() = %v, want %v", got, base)
+			}
+		})
+	}
+}
+
+func TestSmgpTimeMonthEnd(t *testing.T) {
+	tests := []struct {
+		s    SmgpTime
+		want time.Time
+	}{
+		{"240229120000000+", time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"260131120000000+", time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)},
+		{"260430120000000+", time.Date(2026, 4, 30, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := tt.s.Time()
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("Time(%s) = %v, %v, want %v", tt.s, got, err, tt.want)
+		}
+	}
+}
+
+func TestSmgpTimeInvalid(t *testing.T) {
+	tests := []SmgpTime{
+		"26011009000000",
+		"260110090000057+",
+		"260110090000000*",
+		"261310090000000+",
+		"2601100900000R00",
+		// 字段须全为数字，不接受符号
+		"26+110090000000+",
+		"260110-10000000+",
+		"2601100900000+8+",
+		"+10000000000000R",
+		"0000-1000000000R",
+		// 日超过当月天数
+		"260231090000000+",
+		"260431090000000+",
+		"250229090000000+",
+		"260100090000000+",
+	}
+	for _, s := range tests {
+		if err := s.Validate(); err == nil {
+			t.Errorf("Validate(%s) returned no error", s)
+		}
+	}
+}
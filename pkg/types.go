@@ -95,7 +95,7 @@ func (t *TLV) Byte() ([]byte, error) {
 }
 
 func (t *TLV) String() string {
-	return ""
+	return fmt.Sprintf("%v(%d): %x", t.Tag, t.Length, t.Value)
 }
 
 func packUi16(n uint16) []byte {
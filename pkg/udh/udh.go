@@ -0,0 +1,199 @@
+// Package udh 编解码短消息用户数据头(UDH)中的信息元素(IE)，
+// 用于长短信、端口短信(WAP Push、应用端口短信)与特殊短信指示。
+package udh
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidUDH = errors.New("udh: invalid user data header")
+	ErrIETooLong  = errors.New("udh: information element too long")
+)
+
+// 信息元素标识 IEI
+const (
+	IEI_CONCAT_8    uint8 = 0x00 // 长短信，8位参考号
+	IEI_SPECIAL_SMS uint8 = 0x01 // 特殊短信指示
+	IEI_PORT_8      uint8 = 0x04 // 应用端口寻址，8位端口号
+	IEI_PORT_16     uint8 = 0x05 // 应用端口寻址，16位端口号
+	IEI_CONCAT_16   uint8 = 0x08 // 长短信，16位参考号
+)
+
+// 常用端口
+const (
+	PORT_WAP_PUSH uint16 = 2948 // WAP Push 目的端口
+	PORT_WAP_WSP  uint16 = 9200 // WAP 无连接 WSP 源端口
+)
+
+// 信息元素
+type IE struct {
+	ID   uint8
+	Data []byte
+}
+
+func (ie IE) Len() int {
+	return 2 + len(ie.Data)
+}
+
+func (ie IE) String() string {
+	return fmt.Sprintf("IEI(0x%02x): %x", ie.ID, ie.Data)
+}
+
+func Concat8(ref, total, seq uint8) IE {
+	return IE{ID: IEI_CONCAT_8, Data: []byte{ref, total, seq}}
+}
+
+func Concat16(ref uint16, total, seq uint8) IE {
+	return IE{ID: IEI_CONCAT_16, Data: []byte{byte(ref >> 8), byte(ref), total, seq}}
+}
+
+func Port8(dst, src uint8) IE {
+	return IE{ID: IEI_PORT_8, Data: []byte{dst, src}}
+}
+
+func Port16(dst, src uint16) IE {
+	return IE{ID: IEI_PORT_16, Data: []byte{byte(dst >> 8), byte(dst), byte(src >> 8), byte(src)}}
+}
+
+// 特殊短信指示类型
+const (
+	SPECIAL_VOICE uint8 = 0 // 语音留言
+	SPECIAL_FAX   uint8 = 1 // 传真
+	SPECIAL_EMAIL uint8 = 2 // 电子邮件
+	SPECIAL_OTHER uint8 = 3 // 其它
+)
+
+// store 为 true 时终端保存该短消息，否则可丢弃；count 为待取消息数
+func SpecialSMS(typ uint8, store bool, count uint8) IE {
+	b := typ & 0x7f
+	if store {
+		b |= 0x80
+	}
+	return IE{ID: IEI_SPECIAL_SMS, Data: []byte{b, count}}
+}
+
+// 长短信分段信息
+type ConcatInfo struct {
+	Ref   uint16
+	Total uint8
+	Seq   uint8
+	Ref16 bool
+}
+
+// 端口寻址信息
+type PortInfo struct {
+	Dst    uint16
+	Src    uint16
+	Port16 bool
+}
+
+// 特殊短信指示信息
+type SpecialSMSInfo struct {
+	Type  uint8
+	Store bool
+	Count uint8
+}
+
+// 用户数据头，由若干信息元素组成
+type Header []IE
+
+// 编码后的长度，含 UDHL 字节；没有信息元素时为0
+func (h Header) Len() int {
+	if len(h) == 0 {
+		return 0
+	}
+	n := 1
+	for _, ie := range h {
+		n += ie.Len()
+	}
+	return n
+}
+
+// 编码为 UDHL + IEs，没有信息元素时返回 nil
+func (h Header) Bytes() ([]byte, error) {
+	if len(h) == 0 {
+		return nil, nil
+	}
+	if h.Len()-1 > 0xff {
+		return nil, ErrIETooLong
+	}
+	b := make([]byte, 1, h.Len())
+	b[0] = byte(h.Len() - 1)
+	for _, ie := range h {
+		if len(ie.Data) > 0xff {
+			return nil, ErrIETooLong
+		}
+		b = append(b, ie.ID, byte(len(ie.Data)))
+		b = append(b, ie.Data...)
+	}
+	return b, nil
+}
+
+// 查找第一个标识为 id 的信息元素
+func (h Header) Find(id uint8) (IE, bool) {
+	for _, ie := range h {
+		if ie.ID == id {
+			return ie, true
+		}
+	}
+	return IE{}, false
+}
+
+func (h Header) Concat() (ConcatInfo, bool) {
+	for _, ie := range h {
+		switch {
+		case ie.ID == IEI_CONCAT_8 && len(ie.Data) == 3:
+			return ConcatInfo{Ref: uint16(ie.Data[0]), Total: ie.Data[1], Seq: ie.Data[2]}, true
+		case ie.ID == IEI_CONCAT_16 && len(ie.Data) == 4:
+			return ConcatInfo{Ref: uint16(ie.Data[0])<<8 | uint16(ie.Data[1]), Total: ie.Data[2], Seq: ie.Data[3], Ref16: true}, true
+		}
+	}
+	return ConcatInfo{}, false
+}
+
+func (h Header) Port() (PortInfo, bool) {
+	for _, ie := range h {
+		switch {
+		case ie.ID == IEI_PORT_8 && len(ie.Data) == 2:
+			return PortInfo{Dst: uint16(ie.Data[0]), Src: uint16(ie.Data[1])}, true
+		case ie.ID == IEI_PORT_16 && len(ie.Data) == 4:
+			return PortInfo{
+				Dst:    uint16(ie.Data[0])<<8 | uint16(ie.Data[1]),
+				Src:    uint16(ie.Data[2])<<8 | uint16(ie.Data[3]),
+				Port16: true,
+			}, true
+		}
+	}
+	return PortInfo{}, false
+}
+
+func (h Header) SpecialSMS() (SpecialSMSInfo, bool) {
+	ie, ok := h.Find(IEI_SPECIAL_SMS)
+	if !ok || len(ie.Data) != 2 {
+		return SpecialSMSInfo{}, false
+	}
+	return SpecialSMSInfo{Type: ie.Data[0] & 0x7f, Store: ie.Data[0]&0x80 != 0, Count: ie.Data[1]}, true
+}
+
+// 解析以 UDHL 开头的用户数据，返回用户数据头与其后的内容
+func Parse(ud []byte) (Header, []byte, error) {
+	if len(ud) == 0 || int(ud[0])+1 > len(ud) {
+		return nil, nil, ErrInvalidUDH
+	}
+	raw := ud[1 : 1+int(ud[0])]
+	body := ud[1+int(ud[0]):]
+
+	var h Header
+	for len(raw) > 0 {
+		if len(raw) < 2 || 2+int(raw[1]) > len(raw) {
+			return nil, nil, ErrInvalidUDH
+		}
+		data := make([]byte, raw[1])
+		copy(data, raw[2:2+int(raw[1])])
+		h = append(h, IE{ID: raw[0], Data: data})
+		raw = raw[2+int(raw[1]):]
+	}
+	return h, body, nil
+}
@@ -0,0 +1,129 @@
+package udh
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestHeaderRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		h     Header
+		bytes []byte
+	}{
+		{"concat8", Header{Concat8(0x12, 3, 1)}, []byte{5, 0x00, 3, 0x12, 3, 1}},
+		{"concat16", Header{Concat16(0x1234, 3, 2)}, []byte{6, 0x08, 4, 0x12, 0x34, 3, 2}},
+		{"port8", Header{Port8(0xf5, 0xf6)}, []byte{4, 0x04, 2, 0xf5, 0xf6}},
+		{"port16", Header{Port16(PORT_WAP_PUSH, PORT_WAP_WSP)}, []byte{6, 0x05, 4, 0x0b, 0x84, 0x23, 0xf0}},
+		{"special stored", Header{SpecialSMS(SPECIAL_VOICE, true, 2)}, []byte{4, 0x01, 2, 0x80, 2}},
+		{"special discarded", Header{SpecialSMS(SPECIAL_EMAIL, false, 1)}, []byte{4, 0x01, 2, 0x02, 1}},
+		{"port and concat", Header{Port16(0x1580, 0), Concat8(7, 2, 2)},
+			[]byte{11, 0x05, 4, 0x15, 0x80, 0, 0, 0x00, 3, 7, 2, 2}},
+		{"unknown ie", Header{{ID: 0x24, Data: []byte{1}}, {ID: 0x70}}, []byte{5, 0x24, 1, 1, 0x70, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := tt.h.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, tt.bytes) || tt.h.Len() != len(b) {
+				t.Fatalf("Bytes = % x (Len %d), want % x", b, tt.h.Len(), tt.bytes)
+			}
+
+			h, body, err := Parse(append(b, "body"...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != "body" {
+				t.Errorf("body = %q, want %q", body, "body")
+			}
+			for i := range tt.h {
+				if tt.h[i].Data == nil {
+					tt.h[i].Data = []byte{}
+				}
+			}
+			if !reflect.DeepEqual(h, tt.h) {
+				t.Errorf("Parse = %v, want %v", h, tt.h)
+			}
+		})
+	}
+}
+
+func TestHeaderInfo(t *testing.T) {
+	h := Header{SpecialSMS(SPECIAL_FAX, true, 9), Port8(1, 2), Concat16(0xbeef, 4, 3)}
+	if c, ok := h.Concat(); !ok || c != (ConcatInfo{Ref: 0xbeef, Total: 4, Seq: 3, Ref16: true}) {
+		t.Errorf("Concat = %+v, %v", c, ok)
+	}
+	if p, ok := h.Port(); !ok || p != (PortInfo{Dst: 1, Src: 2}) {
+		t.Errorf("Port = %+v, %v", p, ok)
+	}
+	if s, ok := h.SpecialSMS(); !ok || s != (SpecialSMSInfo{Type: SPECIAL_FAX, Store: true, Count: 9}) {
+		t.Errorf("SpecialSMS = %+v, %v", s, ok)
+	}
+
+	h = Header{Concat8(1, 2, 1), Port16(2948, 9200)}
+	if c, ok := h.Concat(); !ok || c != (ConcatInfo{Ref: 1, Total: 2, Seq: 1}) {
+		t.Errorf("Concat = %+v, %v", c, ok)
+	}
+	if p, ok := h.Port(); !ok || p != (PortInfo{Dst: 2948, Src: 9200, Port16: true}) {
+		t.Errorf("Port = %+v, %v", p, ok)
+	}
+
+	// IEL 与类型不符的信息元素不采用
+	h = Header{{ID: IEI_CONCAT_8, Data: []byte{1, 2}}, {ID: IEI_PORT_16, Data: []byte{1, 2}}, {ID: IEI_SPECIAL_SMS, Data: []byte{1}}}
+	if _, ok := h.Concat(); ok {
+		t.Error("Concat accepted a short IE")
+	}
+	if _, ok := h.Port(); ok {
+		t.Error("Port accepted a short IE")
+	}
+	if _, ok := h.SpecialSMS(); ok {
+		t.Error("SpecialSMS accepted a short IE")
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		ud   []byte
+	}{
+		{"empty", nil},
+		{"udhl beyond data", []byte{5, 0x00, 3, 1, 2}},
+		{"udhl beyond data by one", []byte{6, 0x08, 4, 0x12, 0x34, 3}},
+		{"ie header truncated", []byte{1, 0x00}},
+		{"iel beyond udhl", []byte{4, 0x00, 3, 1, 2, 1}},
+		{"second iel beyond udhl", []byte{6, 0x00, 0, 0x05, 4, 1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		if h, body, err := Parse(tt.ud); err != ErrInvalidUDH || h != nil || body != nil {
+			t.Errorf("%s: Parse(% x) = %v, %q, %v, want %v", tt.name, tt.ud, h, body, err, ErrInvalidUDH)
+		}
+	}
+
+	// UDHL 为0表示没有信息元素
+	h, body, err := Parse([]byte{0, 'a'})
+	if err != nil || len(h) != 0 || string(body) != "a" {
+		t.Errorf("Parse(empty header) = %v, %q, %v", h, body, err)
+	}
+}
+
+func TestBytesErrors(t *testing.T) {
+	if b, err := (Header{}).Bytes(); b != nil || err != nil {
+		t.Errorf("empty header Bytes = % x, %v, want nil", b, err)
+	}
+	if n := (Header{}).Len(); n != 0 {
+		t.Errorf("empty header Len = %d, want 0", n)
+	}
+
+	long := Header{{ID: 0x70, Data: make([]byte, 256)}}
+	if _, err := long.Bytes(); err != ErrIETooLong {
+		t.Errorf("256-byte IE error = %v, want %v", err, ErrIETooLong)
+	}
+	// 单个 IE 不超长，但 UDHL 超过255
+	many := Header{{ID: 0x70, Data: make([]byte, 200)}, {ID: 0x71, Data: make([]byte, 60)}}
+	if _, err := many.Bytes(); err != ErrIETooLong {
+		t.Errorf("UDHL %d error = %v, want %v", many.Len()-1, err, ErrIETooLong)
+	}
+}
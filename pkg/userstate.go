@@ -0,0 +1,120 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+)
+
+const (
+	SmgpQueryUserStateReqPktLen  = HeaderPktLen + 21 + 6    //39d, 0x27
+	SmgpQueryUserStateRespPktLen = HeaderPktLen + 4 + 1 + 4 //21d, 0x15
+)
+
+// 用户状态 UserStatus
+type UserState uint8
+
+const (
+	USER_NORMAL    UserState = iota // 正常
+	USER_SUSPENDED                  // 停机
+	USER_NOT_EXIST                  // 号码不存在
+)
+
+func (s UserState) String() string {
+	switch s {
+	case USER_NORMAL:
+		return "正常"
+	case USER_SUSPENDED:
+		return "停机"
+	case USER_NOT_EXIST:
+		return "号码不存在"
+	}
+	return "UserState Unknown: " + strconv.Itoa(int(s))
+}
+
+// 用户是否可以接收短消息
+func (s UserState) CanReceive() bool {
+	return s == USER_NORMAL
+}
+
+// 查询用户状态
+type SmgpQueryUserStateReqPkt struct {
+	QueryUserAddr string // 查询的用户号码
+	SMGWNo        string // 发起查询的网关代码
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpQueryUserStateReqPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpQueryUserStateReqPktLen)
+	// header
+	w.WriteHeader(SmgpQueryUserStateReqPktLen, seqId, SMGP_QUERY_USERSTATE)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteFixedSizeString(p.QueryUserAddr, 21)
+	w.WriteFixedSizeString(p.SMGWNo, 6)
+
+	return w.Bytes()
+}
+
+func (p *SmgpQueryUserStateReqPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	p.QueryUserAddr = string(r.ReadCString(21))
+	p.SMGWNo = string(r.ReadCString(6))
+
+	return r.Error()
+}
+
+func (p *SmgpQueryUserStateReqPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Query UserState Req ---")
+	fmt.Fprintln(&b, "QueryUserAddr: ", p.QueryUserAddr)
+	fmt.Fprintln(&b, "SMGWNo: ", p.SMGWNo)
+	return b.String()
+}
+
+type SmgpQueryUserStateRespPkt struct {
+	Status     Status
+	UserStatus UserState // 用户状态
+	Count      uint32    // 网关中该用户待下发的消息数
+
+	// used in session
+	SequenceID uint32
+}
+
+func (p *SmgpQueryUserStateRespPkt) Pack(seqId uint32) ([]byte, error) {
+	var w = newPkgWriter(SmgpQueryUserStateRespPktLen)
+	// header
+	w.WriteHeader(SmgpQueryUserStateRespPktLen, seqId, SMGP_QUERY_USERSTATE_RESP)
+	p.SequenceID = seqId
+
+	// body
+	w.WriteInt(binary.BigEndian, p.Status)
+	w.WriteByte(uint8(p.UserStatus))
+	w.WriteInt(binary.BigEndian, p.Count)
+
+	return w.Bytes()
+}
+
+func (p *SmgpQueryUserStateRespPkt) Unpack(data []byte) error {
+	var r = newPkgReader(data)
+
+	r.ReadInt(binary.BigEndian, &p.Status)
+	p.UserStatus = UserState(r.ReadByte())
+	r.ReadInt(binary.BigEndian, &p.Count)
+
+	return r.Error()
+}
+
+func (p *SmgpQueryUserStateRespPkt) String() string {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "--- SMGP Query UserState Resp ---")
+	fmt.Fprintln(&b, "Status: ", p.Status)
+	fmt.Fprintln(&b, "UserStatus: ", p.UserStatus)
+	fmt.Fprintln(&b, "Count: ", p.Count)
+	return b.String()
+}
@@ -7,7 +7,6 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
-	"math"
 	"strconv"
 	"time"
 	"unicode/utf8"
@@ -87,25 +86,20 @@ func GenAuthenticatorServer(status Status, secret, AuthenticatorClient string) (
 //时间：4字节（BCD码），格式为MMDDHHMM（月日时分）
 //序列号：3字节（BCD码），取值范围为000000～999999，从0开始，顺序累加，步长为1，循环使用。
 //例如某SMGW的代码为010061，在2003年1月16日下午5时0分收到一条短消息，这条短消息的MsgID为：0x01006101161700012345，其中010061表示SMGW代码，01161700表示接收时间，012345表示消息序列号。
-// MsgID 无法用数字的形式存储，16进制字符串，总长度 10*2
-func GenMsgID(spId string, sequenceNum uint32) (string, error) {
-	now := time.Now()
-	month := now.Month()
-	day := now.Day()
-	hour := now.Hour()
-	min := now.Minute()
-	spIdInt, _ := strconv.ParseInt(spId, 10, 24)
-	return fmt.Sprintf("%06x%02d%02d%02d%02d%06x", spIdInt, month, day, hour, min, sequenceNum), nil
+// MsgID 无法用数字的形式存储，16进制字符串，总长度 10*2，见 MsgID
+// spId 为 SMGW 代码，不足6位时左补0
+func GenMsgID(spId string, sequenceNum uint32) (MsgID, error) {
+	return NewMsgID(spId, time.Now(), sequenceNum)
 }
 
 func UnpackMsgId(msgId string) string {
-	spId, _ := strconv.ParseUint(msgId[:6], 16, 24)
-	month, _ := strconv.ParseUint(msgId[6:8], 10, 8)
-	day, _ := strconv.ParseUint(msgId[8:10], 10, 8)
-	hour, _ := strconv.ParseUint(msgId[10:12], 10, 8)
-	min, _ := strconv.ParseUint(msgId[12:14], 10, 8)
-	seqNum, _ := strconv.ParseUint(msgId[14:], 16, 24)
-	return fmt.Sprintf("spId: %s, month: %d, day: %d, hour: %d, min: %d, seqNum: %d, ", NewOctetString(strconv.Itoa(int(spId))).FixedString(6), month, day, hour, min, seqNum)
+	id, err := ParseMsgID(msgId)
+	if err != nil {
+		return err.Error()
+	}
+	seqNum, _ := id.Sequence()
+	ts := id.Timestamp()
+	return fmt.Sprintf("spId: %s, month: %s, day: %s, hour: %s, min: %s, seqNum: %d, ", id.GatewayCode(), ts[0:2], ts[2:4], ts[4:6], ts[6:8], seqNum)
 }
 
 func Utf8ToUcs2(in string) (string, error) {
@@ -156,81 +150,28 @@ func GB18030ToUtf8(in string) (string, error) {
 	return string(out), nil
 }
 
-var TpUdhiSeq byte = 0x00
-
+// content 为 UCS2 编码，按字符切分，参考号取自包内默认分配器，
+// 需要按连接分配参考号时使用 Splitter
 func SplitLongSms(content string) [][]byte {
-	smsLength := 140
-	smsHeaderLength := 6
-	smsBodyLen := smsLength - smsHeaderLength
-	contentBytes := []byte(content)
-	var chunks [][]byte
-	num := 1
-	if (len(content)) > 140 {
-		num = int(math.Ceil(float64(len(content)) / float64(smsBodyLen)))
-	}
-	if num == 1 {
-		chunks = append(chunks, contentBytes)
-		return chunks
-	}
-	tpUdhiHeader := []byte{0x05, 0x00, 0x03, TpUdhiSeq, byte(num)}
-	TpUdhiSeq++
-
-	for i := 0; i < num; i++ {
-		chunk := tpUdhiHeader
-		chunk = append(chunk, byte(i+1))
-		bodyLen := smsLength - smsHeaderLength
-		offset := i * bodyLen
-		max := offset + bodyLen
-		if max > len(content) {
-			max = len(content)
-		}
-
-		chunk = append(chunk, contentBytes[offset:max]...)
-		chunks = append(chunks, chunk)
+	chunks, err := NewSplitter(UCS2, nil).Split([]byte(content))
+	if err != nil {
+		chunks, _ = NewSplitter(BINARY, nil).Split([]byte(content))
 	}
 	return chunks
 }
 
+// pkg.MsgContent 为 UTF-8 文本，按 UCS2 编码切分，见 EncodeText
 func GetMsgPkgs(pkg *SmgpSubmitReqPkt) ([]*SmgpSubmitReqPkt, error) {
-	packets := make([]*SmgpSubmitReqPkt, 0)
-	content, err := Utf8ToUcs2(pkg.MsgContent)
+	text, err := EncodeText(string(pkg.MsgContent), ENCODE_UCS2) // 长短信必须使用 UCS2 格式
 	if err != nil {
-		return packets, err
-	}
-
-	chunks := SplitLongSms(content)
-	var tpUdhi uint8
-	if len(chunks) > 1 {
-		tpUdhi = 1
+		return make([]*SmgpSubmitReqPkt, 0), err
 	}
 
-	for i, chunk := range chunks {
-		p := &SmgpSubmitReqPkt{
-			MsgType:         pkg.MsgType,
-			NeedReport:      pkg.NeedReport,
-			Priority:        pkg.Priority,
-			ServiceID:       pkg.ServiceID,
-			FeeType:         pkg.FeeType,
-			FeeCode:         pkg.FeeCode,
-			FixedFee:        pkg.FixedFee,
-			MsgFormat:       UCS2, // 长短信必须使用 UCS2 格式
-			ValidTime:       pkg.ValidTime,
-			AtTime:          pkg.AtTime,
-			SrcTermID:       pkg.SrcTermID,
-			ChargeTermID:    pkg.ChargeTermID,
-			DestTermIDCount: pkg.DestTermIDCount,
-			DestTermID:      pkg.DestTermID,
-			MsgLength:       uint8(len(chunk)),
-			MsgContent:      string(chunk),
-			Reserve:         pkg.Reserve,
-			Options: Options{
-				TAG_TP_pid:   NewTLV(TAG_TP_pid, []byte{0}),
-				TAG_TP_udhi:  NewTLV(TAG_TP_udhi, []byte{tpUdhi}),
-				TAG_PkTotal:  NewTLV(TAG_PkTotal, []byte{uint8(len(chunks))}),
-				TAG_PkNumber: NewTLV(TAG_PkNumber, []byte{uint8(i + 1)}),
-			},
-		}
-		packets = append(packets, p)
+	tmpl := *pkg
+	tmpl.Options = Options{}
+	packets := text.Submits(&tmpl)
+	for _, p := range packets {
+		p.Options.SetTPPid(0)
 	}
 	return packets, nil
 }
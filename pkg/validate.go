@@ -0,0 +1,210 @@
+package pkg
+
+import (
+	"fmt"
+)
+
+// 字段校验错误，Status 为该错误对应的 SMGP 应答状态，
+// 服务端可直接将其填入应答包
+type ValidationError struct {
+	Status Status
+	Field  string
+	Reason string
+}
+
+func NewValidationError(status Status, field, reason string) *ValidationError {
+	return &ValidationError{
+		Status: status,
+		Field:  field,
+		Reason: reason,
+	}
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s (%d: %s)", e.Field, e.Reason, uint32(e.Status), e.Status)
+}
+
+// 返回错误对应的应答状态，非 ValidationError 时返回 10（消息结构错）
+func ErrorStatus(err error) Status {
+	if err == nil {
+		return STAT_OK
+	}
+	if e, ok := err.(*ValidationError); ok {
+		return e.Status
+	}
+	return STAT_MSG_STRUCT_ERR
+}
+
+// 校验定长字段的长度
+func checkFieldSize(status Status, field, s string, size int) error {
+	if len(s) > size {
+		return NewValidationError(status, field, fmt.Sprintf("length %d exceeds %d", len(s), size))
+	}
+	return nil
+}
+
+// 校验不超过 size 位的数字字符串，允许为空
+func checkDigits(status Status, field, s string, size int) error {
+	if err := checkFieldSize(status, field, s, size); err != nil {
+		return err
+	}
+	if !isDigits(s) {
+		return NewValidationError(status, field, fmt.Sprintf("%q is not numeric", s))
+	}
+	return nil
+}
+
+// 校验终端号码，required 为 true 时不允许为空
+func checkTermID(status Status, field, s string, required bool) error {
+	if required && s == "" {
+		return NewValidationError(status, field, "empty")
+	}
+	return checkFieldSize(status, field, s, 21)
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func validMsgType(t uint8) bool {
+	return t == MO || t == MT || t == P2P
+}
+
+func validMsgFormat(f uint8) bool {
+	switch f {
+	case ASCII, WRITE_CARD, BINARY, UCS2, GB18030:
+		return true
+	}
+	return false
+}
+
+// 校验可选参数长度
+func (o Options) Validate() error {
+	for _, t := range o.List() {
+		if int(t.Length) != len(t.Value) {
+			return NewValidationError(STAT_MSG_STRUCT_ERR, t.Tag.String(),
+				fmt.Sprintf("Length %d not equal to value length %d", t.Length, len(t.Value)))
+		}
+		if err := checkTagLength(t.Tag, len(t.Value)); err != nil {
+			return NewValidationError(STAT_MSG_STRUCT_ERR, t.Tag.String(), err.Error())
+		}
+	}
+	return nil
+}
+
+func (p *SmgpSubmitReqPkt) Validate() error {
+	if !validMsgType(p.MsgType) {
+		return NewValidationError(STAT_MSG_TYPE_ERR, "MsgType", fmt.Sprintf("%d", p.MsgType))
+	}
+	if p.Priority > HIGHEST_PRIORITY {
+		return NewValidationError(STAT_PRIORITY_ERR, "Priority", fmt.Sprintf("%d out of range 0-3", p.Priority))
+	}
+	if err := checkFieldSize(STAT_SERVICE_ID_ERR, "ServiceID", p.ServiceID, 10); err != nil {
+		return err
+	}
+	if err := checkDigits(STAT_FEE_TYPE_ERR, "FeeType", p.FeeType, 2); err != nil {
+		return err
+	}
+	if err := checkDigits(STAT_FEE_CODE_ERR, "FeeCode", p.FeeCode, 6); err != nil {
+		return err
+	}
+	if err := checkDigits(STAT_FIXED_FEE_ERR, "FixedFee", p.FixedFee, 6); err != nil {
+		return err
+	}
+	if !validMsgFormat(p.MsgFormat) {
+		return NewValidationError(STAT_MSG_FORMAT_ERR, "MsgFormat", fmt.Sprintf("%d", p.MsgFormat))
+	}
+	if p.ValidTime.Validate() != nil {
+		return NewValidationError(STAT_VALID_TIME_ERR, "ValidTime", fmt.Sprintf("%q", p.ValidTime))
+	}
+	if p.AtTime.Validate() != nil {
+		return NewValidationError(STAT_AT_TIME_ERR, "AtTime", fmt.Sprintf("%q", p.AtTime))
+	}
+	if err := checkTermID(STAT_SRC_TERM_ID_ERR, "SrcTermID", p.SrcTermID, true); err != nil {
+		return err
+	}
+	if err := checkTermID(STAT_CHARGE_TERM_ID_ERR, "ChargeTermID", p.ChargeTermID, false); err != nil {
+		return err
+	}
+	if len(p.DestTermID) == 0 || len(p.DestTermID) > MaxDestTermIDCount {
+		return NewValidationError(STAT_DEST_TERM_ID_ERR, "DestTermID",
+			fmt.Sprintf("count %d out of range 1-%d", len(p.DestTermID), MaxDestTermIDCount))
+	}
+	if int(p.DestTermIDCount) != len(p.DestTermID) {
+		return NewValidationError(STAT_DEST_TERM_ID_ERR, "DestTermIDCount",
+			fmt.Sprintf("%d not equal to %d DestTermID", p.DestTermIDCount, len(p.DestTermID)))
+	}
+	for _, d := range p.DestTermID {
+		if err := checkTermID(STAT_DEST_TERM_ID_ERR, "DestTermID", d, true); err != nil {
+			return err
+		}
+	}
+	if len(p.MsgContent) > MaxMsgLength || int(p.MsgLength) != len(p.MsgContent) {
+		return NewValidationError(STAT_MSG_LENGTH_ERR, "MsgLength",
+			fmt.Sprintf("%d, content length %d", p.MsgLength, len(p.MsgContent)))
+	}
+	if err := checkFieldSize(STAT_MSG_STRUCT_ERR, "Reserve", p.Reserve, 8); err != nil {
+		return err
+	}
+	return p.Options.Validate()
+}
+
+func (p *SmgpDeliverReqPkt) Validate() error {
+	if p.IsReport != NOT_REPORT && p.IsReport != IS_REPORT {
+		return NewValidationError(STAT_MSG_STRUCT_ERR, "IsReport", fmt.Sprintf("%d", p.IsReport))
+	}
+	if !validMsgFormat(p.MsgFormat) {
+		return NewValidationError(STAT_MSG_FORMAT_ERR, "MsgFormat", fmt.Sprintf("%d", p.MsgFormat))
+	}
+	if p.RecvTime != "" && (len(p.RecvTime) != 14 || !isDigits(p.RecvTime)) {
+		return NewValidationError(STAT_TIME_FORMAT_ERR, "RecvTime", fmt.Sprintf("%q", p.RecvTime))
+	}
+	if err := checkTermID(STAT_SRC_TERM_ID_ERR, "SrcTermID", p.SrcTermID, true); err != nil {
+		return err
+	}
+	if err := checkTermID(STAT_DEST_TERM_ID_ERR, "DestTermID", p.DestTermID, true); err != nil {
+		return err
+	}
+	if len(p.MsgContent) > MaxMsgLength || int(p.MsgLength) != len(p.MsgContent) {
+		return NewValidationError(STAT_MSG_LENGTH_ERR, "MsgLength",
+			fmt.Sprintf("%d, content length %d", p.MsgLength, len(p.MsgContent)))
+	}
+	if err := checkFieldSize(STAT_MSG_STRUCT_ERR, "Reserve", p.Reserve, 8); err != nil {
+		return err
+	}
+	return p.Options.Validate()
+}
+
+func (p *SmgpLoginReqPkt) Validate() error {
+	if p.ClientID == "" {
+		return NewValidationError(STAT_AUTH_ERR, "ClientID", "empty")
+	}
+	if err := checkFieldSize(STAT_AUTH_ERR, "ClientID", p.ClientID, 8); err != nil {
+		return err
+	}
+	if err := checkFieldSize(STAT_AUTH_ERR, "Secret", p.Secret, 15); err != nil {
+		return err
+	}
+	if p.LoginMode > TRANSMIT_MODE {
+		return NewValidationError(STAT_MSG_STRUCT_ERR, "LoginMode", fmt.Sprintf("%d", p.LoginMode))
+	}
+	return nil
+}
+
+func (p *SmgpQueryReqPkt) Validate() error {
+	if len(p.QueryTime) != 8 || !isDigits(p.QueryTime) {
+		return NewValidationError(STAT_TIME_FORMAT_ERR, "QueryTime", fmt.Sprintf("%q", p.QueryTime))
+	}
+	if p.QueryType != QUERY_TOTAL && p.QueryType != QUERY_SERVICE {
+		return NewValidationError(STAT_QUERY_TYPE_ERR, "QueryType", fmt.Sprintf("%d", p.QueryType))
+	}
+	if p.QueryType == QUERY_SERVICE && p.QueryCode == "" {
+		return NewValidationError(STAT_SERVICE_ID_ERR, "QueryCode", "empty")
+	}
+	return checkFieldSize(STAT_SERVICE_ID_ERR, "QueryCode", p.QueryCode, 10)
+}
@@ -0,0 +1,176 @@
+package pkg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type validator interface {
+	Validate() error
+}
+
+type validateCase struct {
+	name   string
+	p      validator
+	status Status
+	field  string
+}
+
+func checkValidate(t *testing.T, tests []validateCase) {
+	t.Helper()
+	for _, tt := range tests {
+		err := tt.p.Validate()
+		if tt.status == STAT_OK {
+			if err != nil {
+				t.Errorf("%s: Validate = %v, want nil", tt.name, err)
+			}
+			continue
+		}
+		ve, ok := err.(*ValidationError)
+		if !ok {
+			t.Errorf("%s: Validate = %v, want a ValidationError", tt.name, err)
+			continue
+		}
+		if ve.Status != tt.status || ve.Field != tt.field {
+			t.Errorf("%s: Validate = %s/%d, want %s/%d", tt.name, ve.Field, ve.Status, tt.field, tt.status)
+		}
+		if ErrorStatus(err) != tt.status {
+			t.Errorf("%s: ErrorStatus = %d, want %d", tt.name, ErrorStatus(err), tt.status)
+		}
+	}
+}
+
+func validSubmit(modify func(p *SmgpSubmitReqPkt)) *SmgpSubmitReqPkt {
+	p := &SmgpSubmitReqPkt{
+		MsgType:         MT,
+		ServiceID:       "TEST",
+		FeeType:         "01",
+		FeeCode:         "000100",
+		FixedFee:        "000000",
+		MsgFormat:       ASCII,
+		ValidTime:       "260117090000032+",
+		AtTime:          "000001000000000R",
+		SrcTermID:       "10661",
+		DestTermIDCount: 1,
+		DestTermID:      []string{"8618000000001"},
+		MsgLength:       2,
+		MsgContent:      []byte("hi"),
+	}
+	p.Options.SetLinkID("link")
+	if modify != nil {
+		modify(p)
+	}
+	return p
+}
+
+func TestSubmitValidate(t *testing.T) {
+	long := strings.Repeat("1", 22)
+	checkValidate(t, []validateCase{
+		{"valid", validSubmit(nil), STAT_OK, ""},
+		{"empty times", validSubmit(func(p *SmgpSubmitReqPkt) { p.ValidTime, p.AtTime = "", "" }), STAT_OK, ""},
+		{"MsgType", validSubmit(func(p *SmgpSubmitReqPkt) { p.MsgType = 9 }), STAT_MSG_TYPE_ERR, "MsgType"},
+		{"Priority", validSubmit(func(p *SmgpSubmitReqPkt) { p.Priority = HIGHEST_PRIORITY + 1 }), STAT_PRIORITY_ERR, "Priority"},
+		{"ServiceID", validSubmit(func(p *SmgpSubmitReqPkt) { p.ServiceID = "12345678901" }), STAT_SERVICE_ID_ERR, "ServiceID"},
+		{"FeeType", validSubmit(func(p *SmgpSubmitReqPkt) { p.FeeType = "0a" }), STAT_FEE_TYPE_ERR, "FeeType"},
+		{"FeeType length", validSubmit(func(p *SmgpSubmitReqPkt) { p.FeeType = "001" }), STAT_FEE_TYPE_ERR, "FeeType"},
+		{"FeeCode", validSubmit(func(p *SmgpSubmitReqPkt) { p.FeeCode = "-1" }), STAT_FEE_CODE_ERR, "FeeCode"},
+		{"FixedFee", validSubmit(func(p *SmgpSubmitReqPkt) { p.FixedFee = "1000000" }), STAT_FIXED_FEE_ERR, "FixedFee"},
+		{"MsgFormat", validSubmit(func(p *SmgpSubmitReqPkt) { p.MsgFormat = 7 }), STAT_MSG_FORMAT_ERR, "MsgFormat"},
+		{"ValidTime", validSubmit(func(p *SmgpSubmitReqPkt) { p.ValidTime = "260230090000032+" }), STAT_VALID_TIME_ERR, "ValidTime"},
+		{"AtTime", validSubmit(func(p *SmgpSubmitReqPkt) { p.AtTime = "2601170900" }), STAT_AT_TIME_ERR, "AtTime"},
+		{"SrcTermID empty", validSubmit(func(p *SmgpSubmitReqPkt) { p.SrcTermID = "" }), STAT_SRC_TERM_ID_ERR, "SrcTermID"},
+		{"SrcTermID length", validSubmit(func(p *SmgpSubmitReqPkt) { p.SrcTermID = long }), STAT_SRC_TERM_ID_ERR, "SrcTermID"},
+		{"ChargeTermID", validSubmit(func(p *SmgpSubmitReqPkt) { p.ChargeTermID = long }), STAT_CHARGE_TERM_ID_ERR, "ChargeTermID"},
+		{"DestTermID none", validSubmit(func(p *SmgpSubmitReqPkt) { p.DestTermID, p.DestTermIDCount = nil, 0 }), STAT_DEST_TERM_ID_ERR, "DestTermID"},
+		{"DestTermID too many", validSubmit(func(p *SmgpSubmitReqPkt) {
+			p.DestTermID = make([]string, MaxDestTermIDCount+1)
+			for i := range p.DestTermID {
+				p.DestTermID[i] = "8618000000001"
+			}
+			p.DestTermIDCount = uint8(len(p.DestTermID))
+		}), STAT_DEST_TERM_ID_ERR, "DestTermID"},
+		{"DestTermIDCount", validSubmit(func(p *SmgpSubmitReqPkt) { p.DestTermIDCount = 2 }), STAT_DEST_TERM_ID_ERR, "DestTermIDCount"},
+		{"DestTermID empty", validSubmit(func(p *SmgpSubmitReqPkt) {
+			p.DestTermID, p.DestTermIDCount = []string{"8618000000001", ""}, 2
+		}), STAT_DEST_TERM_ID_ERR, "DestTermID"},
+		{"DestTermID length", validSubmit(func(p *SmgpSubmitReqPkt) { p.DestTermID[0] = long }), STAT_DEST_TERM_ID_ERR, "DestTermID"},
+		{"MsgLength", validSubmit(func(p *SmgpSubmitReqPkt) { p.MsgLength = 3 }), STAT_MSG_LENGTH_ERR, "MsgLength"},
+		{"MsgContent", validSubmit(func(p *SmgpSubmitReqPkt) { p.MsgContent = make([]byte, MaxMsgLength+1) }), STAT_MSG_LENGTH_ERR, "MsgLength"},
+		{"Reserve", validSubmit(func(p *SmgpSubmitReqPkt) { p.Reserve = "123456789" }), STAT_MSG_STRUCT_ERR, "Reserve"},
+		{"option length", validSubmit(func(p *SmgpSubmitReqPkt) { p.Options[TAG_LinkID].Length = 19 }), STAT_MSG_STRUCT_ERR, "TAG_LinkID"},
+		{"option size", validSubmit(func(p *SmgpSubmitReqPkt) { p.Options[TAG_TP_udhi] = NewTLV(TAG_TP_udhi, []byte{1, 0}) }), STAT_MSG_STRUCT_ERR, "TAG_TP_udhi"},
+	})
+}
+
+func validDeliver(modify func(p *SmgpDeliverReqPkt)) *SmgpDeliverReqPkt {
+	p := &SmgpDeliverReqPkt{
+		IsReport:   NOT_REPORT,
+		MsgFormat:  GB18030,
+		RecvTime:   "20260117090000",
+		SrcTermID:  "8618000000001",
+		DestTermID: "10661",
+		MsgLength:  2,
+		MsgContent: []byte("hi"),
+	}
+	if modify != nil {
+		modify(p)
+	}
+	return p
+}
+
+func TestDeliverValidate(t *testing.T) {
+	checkValidate(t, []validateCase{
+		{"valid", validDeliver(nil), STAT_OK, ""},
+		{"report", validDeliver(func(p *SmgpDeliverReqPkt) { p.IsReport = IS_REPORT }), STAT_OK, ""},
+		{"empty RecvTime", validDeliver(func(p *SmgpDeliverReqPkt) { p.RecvTime = "" }), STAT_OK, ""},
+		{"IsReport", validDeliver(func(p *SmgpDeliverReqPkt) { p.IsReport = 2 }), STAT_MSG_STRUCT_ERR, "IsReport"},
+		{"MsgFormat", validDeliver(func(p *SmgpDeliverReqPkt) { p.MsgFormat = 5 }), STAT_MSG_FORMAT_ERR, "MsgFormat"},
+		{"RecvTime length", validDeliver(func(p *SmgpDeliverReqPkt) { p.RecvTime = "202601170900" }), STAT_TIME_FORMAT_ERR, "RecvTime"},
+		{"RecvTime digits", validDeliver(func(p *SmgpDeliverReqPkt) { p.RecvTime = "2026011709000x" }), STAT_TIME_FORMAT_ERR, "RecvTime"},
+		{"SrcTermID", validDeliver(func(p *SmgpDeliverReqPkt) { p.SrcTermID = "" }), STAT_SRC_TERM_ID_ERR, "SrcTermID"},
+		{"DestTermID", validDeliver(func(p *SmgpDeliverReqPkt) { p.DestTermID = strings.Repeat("1", 22) }), STAT_DEST_TERM_ID_ERR, "DestTermID"},
+		{"MsgLength", validDeliver(func(p *SmgpDeliverReqPkt) { p.MsgLength = 0 }), STAT_MSG_LENGTH_ERR, "MsgLength"},
+		{"Reserve", validDeliver(func(p *SmgpDeliverReqPkt) { p.Reserve = "123456789" }), STAT_MSG_STRUCT_ERR, "Reserve"},
+		{"option size", validDeliver(func(p *SmgpDeliverReqPkt) {
+			p.Options = NewOptions(NewTLV(TAG_MsgSrc, []byte("short")))
+		}), STAT_MSG_STRUCT_ERR, "TAG_MsgSrc"},
+	})
+}
+
+func TestLoginValidate(t *testing.T) {
+	login := func(clientID, secret string, mode uint8) *SmgpLoginReqPkt {
+		return &SmgpLoginReqPkt{ClientID: clientID, Secret: secret, LoginMode: mode}
+	}
+	checkValidate(t, []validateCase{
+		{"valid", login("10000001", "secret", TRANSMIT_MODE), STAT_OK, ""},
+		{"ClientID empty", login("", "secret", 0), STAT_AUTH_ERR, "ClientID"},
+		{"ClientID length", login("100000001", "secret", 0), STAT_AUTH_ERR, "ClientID"},
+		{"Secret length", login("10000001", strings.Repeat("s", 16), 0), STAT_AUTH_ERR, "Secret"},
+		{"LoginMode", login("10000001", "secret", TRANSMIT_MODE+1), STAT_MSG_STRUCT_ERR, "LoginMode"},
+	})
+}
+
+func TestQueryValidate(t *testing.T) {
+	query := func(time string, typ uint8, code string) *SmgpQueryReqPkt {
+		return &SmgpQueryReqPkt{QueryTime: time, QueryType: typ, QueryCode: code}
+	}
+	checkValidate(t, []validateCase{
+		{"total", query("20260117", QUERY_TOTAL, ""), STAT_OK, ""},
+		{"service", query("20260117", QUERY_SERVICE, "TEST"), STAT_OK, ""},
+		{"QueryTime length", query("260117", QUERY_TOTAL, ""), STAT_TIME_FORMAT_ERR, "QueryTime"},
+		{"QueryTime digits", query("2026O117", QUERY_TOTAL, ""), STAT_TIME_FORMAT_ERR, "QueryTime"},
+		{"QueryType", query("20260117", 2, ""), STAT_QUERY_TYPE_ERR, "QueryType"},
+		{"QueryCode empty", query("20260117", QUERY_SERVICE, ""), STAT_SERVICE_ID_ERR, "QueryCode"},
+		{"QueryCode length", query("20260117", QUERY_SERVICE, "12345678901"), STAT_SERVICE_ID_ERR, "QueryCode"},
+	})
+}
+
+func TestErrorStatus(t *testing.T) {
+	if s := ErrorStatus(nil); s != STAT_OK {
+		t.Errorf("ErrorStatus(nil) = %d, want %d", s, STAT_OK)
+	}
+	if s := ErrorStatus(errors.New("other")); s != STAT_MSG_STRUCT_ERR {
+		t.Errorf("ErrorStatus(other) = %d, want %d", s, STAT_MSG_STRUCT_ERR)
+	}
+}
@@ -12,18 +12,20 @@ const (
 
 // MsgType
 const (
-	MO = 0 // MO消息（终端发给SP）
-	MT = 6 // MT消息（SP发给终端，包括WEB上发送的点对点短消息）
+	MO  = 0 // MO消息（终端发给SP）
+	MT  = 6 // MT消息（SP发给终端，包括WEB上发送的点对点短消息）
+	P2P = 7 // 点对点短消息
 )
 
 // MsgFormat
 // 短消息内容体的编码格式
 // 对于文字短消息，要求MsgFormat＝15, 对于回执消息，要求MsgFormat＝0
 const (
-	ASCII   = 0  // ASCII编码
-	BINARY  = 4  // 二进制短消息
-	UCS2    = 8  // UCS2编码
-	GB18030 = 15 // GB18030编码
+	ASCII      = 0  // ASCII编码
+	WRITE_CARD = 3  // 短信写卡操作
+	BINARY     = 4  // 二进制短消息
+	UCS2       = 8  // UCS2编码
+	GB18030    = 15 // GB18030编码
 )
 
 const (
@@ -44,3 +46,14 @@ const (
 	HIGHER_PRIORITY
 	HIGHEST_PRIORITY
 )
+
+// 查询类别 QueryType
+const (
+	QUERY_TOTAL   = 0 // 总数查询
+	QUERY_SERVICE = 1 // 按业务类型查询
+)
+
+const (
+	MaxDestTermIDCount = 100 // 一次提交的最大接收号码数
+	MaxMsgLength       = 255 // MsgLength 为1字节，短消息内容的最大长度
+)
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"log"
+
+	"github.com/boxtsecond/gosmgp/pkg"
+)
+
+// 预付费计费鉴权，由计费系统实现。
+// 返回非0的 Status 表示拒绝扣费，该 Status 将作为应答状态返回给对端。
+type PaymentApprover interface {
+	ApprovePayment(*pkg.SmgpPaymentRequestReqPkt) (pkg.Status, error)
+}
+
+type PaymentApproverFunc func(*pkg.SmgpPaymentRequestReqPkt) (pkg.Status, error)
+
+func (f PaymentApproverFunc) ApprovePayment(p *pkg.SmgpPaymentRequestReqPkt) (pkg.Status, error) {
+	return f(p)
+}
+
+// PaymentHandler 返回一个在 Submit 被受理前进行计费鉴权的 Handler，
+// 需放在处理 Submit 的 Handler 之前。
+// 鉴权前以 smgwCode 和连接的 SequenceNum 生成 MsgID 并写入 Submit 应答，
+// Payment_Request 带相同的 MsgID，计费系统据此与之后的 Payment_Affirm、状态报告对应；
+// 后续处理 Submit 的 Handler 应沿用应答中已有的 MsgID，不再重新生成。
+// 对每个接收号码生成 Payment_Request 交由 PaymentApprover 鉴权，
+// 任一号码被拒绝时，以拒绝的 Status 应答 Submit 并不再调用后续 Handler。
+// 收到的 Payment_Request 同样交由 PaymentApprover 处理并生成应答。
+// 应答包不是预期的类型时(未创建或被 RegisterPDU 替换)，不修改应答并停止处理，
+// 未经鉴权的 Submit 不会交给后续 Handler。
+func PaymentHandler(smgwCode string, approver PaymentApprover) Handler {
+	return HandlerFunc(func(r *Response, p *Packet, l *log.Logger) (bool, error) {
+		switch req := p.Packer.(type) {
+		case *pkg.SmgpSubmitReqPkt:
+			resp, ok := r.Packer.(*pkg.SmgpSubmitRespPkt)
+			if !ok {
+				l.Printf("payment: unexpected submit response %T\n", r.Packer)
+				return false, nil
+			}
+			if resp.MsgID.IsZero() {
+				msgID, err := pkg.GenMsgID(smgwCode, <-p.Conn.SequenceNum)
+				if err != nil {
+					l.Printf("generate msg id error: %v\n", err)
+					resp.Status = pkg.STAT_SYS_BUSY
+					return false, nil
+				}
+				resp.MsgID = msgID
+			}
+			for _, d := range req.DestTermID {
+				status, err := approver.ApprovePayment(pkg.NewPaymentRequest(req, resp.MsgID, d))
+				if err != nil {
+					l.Printf("approve payment of %s error: %v\n", d, err)
+					resp.Status = pkg.STAT_SYS_BUSY
+					return false, nil
+				}
+				if status != pkg.STAT_OK {
+					l.Printf("payment of %s rejected: %v\n", d, status)
+					resp.Status = status
+					return false, nil
+				}
+			}
+			return true, nil
+
+		case *pkg.SmgpPaymentRequestReqPkt:
+			resp, ok := r.Packer.(*pkg.SmgpPaymentRequestRespPkt)
+			if !ok {
+				l.Printf("payment: unexpected payment request response %T\n", r.Packer)
+				return false, nil
+			}
+			status, err := approver.ApprovePayment(req)
+			if err != nil {
+				l.Printf("approve payment of %s error: %v\n", req.ChargeTermID, err)
+				status = pkg.STAT_SYS_BUSY
+			}
+			resp.Status = status
+			return false, nil
+		}
+		return true, nil
+	})
+}
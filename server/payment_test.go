@@ -0,0 +1,108 @@
+package server
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"net"
+	"testing"
+
+	"github.com/boxtsecond/gosmgp/pkg"
+)
+
+func testConn(t *testing.T) *pkg.Conn {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	rw, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { peer.Close() })
+	c := pkg.NewConnection(rw, pkg.VERSION)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestPaymentHandlerSubmit(t *testing.T) {
+	conn := testConn(t)
+	discard := log.New(ioutil.Discard, "", 0)
+
+	tests := []struct {
+		name   string
+		status pkg.Status
+		err    error
+		next   bool
+		want   pkg.Status
+	}{
+		{"approved", pkg.STAT_OK, nil, true, pkg.STAT_OK},
+		{"rejected", pkg.STAT_FEE_CODE_ERR, nil, false, pkg.STAT_FEE_CODE_ERR},
+		{"approver error", pkg.STAT_OK, errors.New("billing down"), false, pkg.STAT_SYS_BUSY},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []*pkg.SmgpPaymentRequestReqPkt
+			h := PaymentHandler("010061", PaymentApproverFunc(func(p *pkg.SmgpPaymentRequestReqPkt) (pkg.Status, error) {
+				got = append(got, p)
+				return tt.status, tt.err
+			}))
+
+			req := &pkg.SmgpSubmitReqPkt{DestTermIDCount: 2, DestTermID: []string{"8618000000001", "8618000000002"}}
+			resp := &pkg.SmgpSubmitRespPkt{}
+			p := &Packet{Packer: req, Conn: conn}
+			next, err := h.ServeSmgp(&Response{Packet: p, Packer: resp}, p, discard)
+			if err != nil || next != tt.next {
+				t.Fatalf("ServeSmgp = %v, %v, want %v", next, err, tt.next)
+			}
+			if resp.Status != tt.want {
+				t.Errorf("status = %d, want %d", resp.Status, tt.want)
+			}
+			if resp.MsgID.IsZero() || resp.MsgID.GatewayCode() != "010061" {
+				t.Errorf("submit MsgID = %s, want one generated for 010061", resp.MsgID)
+			}
+			if len(got) == 0 {
+				t.Fatal("approver not called")
+			}
+			for _, r := range got {
+				if r.MsgID != resp.MsgID {
+					t.Errorf("payment request MsgID = %s, want %s", r.MsgID, resp.MsgID)
+				}
+			}
+		})
+	}
+}
+
+func TestPaymentHandlerUnexpectedResponse(t *testing.T) {
+	conn := testConn(t)
+	h := PaymentHandler("010061", PaymentApproverFunc(func(*pkg.SmgpPaymentRequestReqPkt) (pkg.Status, error) {
+		t.Error("approver called without a typed response")
+		return pkg.STAT_OK, nil
+	}))
+
+	tests := []struct {
+		req  pkg.Packer
+		resp pkg.Packer
+	}{
+		{&pkg.SmgpSubmitReqPkt{DestTermID: []string{"1"}}, nil},
+		{&pkg.SmgpSubmitReqPkt{DestTermID: []string{"1"}}, &pkg.SmgpDeliverRespPkt{}},
+		{&pkg.SmgpPaymentRequestReqPkt{}, nil},
+		{&pkg.SmgpPaymentRequestReqPkt{}, &pkg.SmgpSubmitRespPkt{}},
+	}
+	for _, tt := range tests {
+		p := &Packet{Packer: tt.req, Conn: conn}
+		r := &Response{Packet: p, Packer: tt.resp}
+		next, err := h.ServeSmgp(r, p, log.New(ioutil.Discard, "", 0))
+		if next || err != nil {
+			t.Errorf("%T with response %T: ServeSmgp = %v, %v, want false, nil", tt.req, tt.resp, next, err)
+		}
+		if r.Packer != tt.resp {
+			t.Errorf("%T: response replaced", tt.req)
+		}
+	}
+}
@@ -50,6 +50,9 @@ type Server struct {
 	T       time.Duration
 	N       int32
 
+	// 用于自动应答 Query_UserState，为空时以 11（命令字错）应答
+	UserState UserStateProvider
+
 	ErrorLog *log.Logger
 }
 
@@ -99,145 +102,50 @@ func (srv *Server) Serve(l net.Listener) error {
 
 func (c *conn) readPacket() (*Response, error) {
 	readTimeout := time.Second * 2
-	i, err := c.Conn.RecvAndUnpackPkt(readTimeout)
+	h, i, err := c.Conn.RecvPkt(readTimeout)
 	if err != nil {
 		return nil, err
 	}
 	ver := c.server.Version
+	id := pkg.RequestID(h.RequestID)
+
+	rsp := &Response{
+		Packet: &Packet{
+			Packer: i,
+			Conn:   c.Conn,
+		},
+	}
+
+	// 请求包按命令字配对自动创建应答包
+	if !id.IsResponse() {
+		r, err := pkg.NewResponse(id, i, h.SequenceID)
+		if err == nil {
+			rsp.Packer = r
+			rsp.SequenceID = h.SequenceID
+		}
+	}
 
-	var rsp *Response
 	switch p := i.(type) {
 	case *pkg.SmgpLoginReqPkt:
 		if p.ClientVersion != ver {
 			return nil, pkg.NewOpError(ErrUnsupportedVersion,
 				fmt.Sprintf("readPacket: receive unsupported version: %#v", p))
 		}
-		rsp = &Response{
-			Packet: &Packet{
-				Packer: p,
-				Conn:   c.Conn,
-			},
-			Packer: &pkg.SmgpLoginRespPkt{
-				SequenceID: p.SequenceID,
-			},
-			SequenceID: p.SequenceID,
-		}
-		c.server.ErrorLog.Printf("receive a smgp login request from %v[%d]\n",
-			c.Conn.RemoteAddr(), p.SequenceID)
-
-	case *pkg.SmgpSubmitReqPkt:
-		rsp = &Response{
-			Packet: &Packet{
-				Packer: p,
-				Conn:   c.Conn,
-			},
-			Packer: &pkg.SmgpSubmitRespPkt{
-				SequenceID: p.SequenceID,
-			},
-			SequenceID: p.SequenceID,
-		}
-		c.server.ErrorLog.Printf("receive a smgp submit request from %v[%d]\n",
-			c.Conn.RemoteAddr(), p.SequenceID)
-
-	case *pkg.SmgpDeliverReqPkt:
-		rsp = &Response{
-			Packet: &Packet{
-				Packer: p,
-				Conn:   c.Conn,
-			},
-			Packer: &pkg.SmgpDeliverRespPkt{
-				SequenceID: p.SequenceID,
-			},
-			SequenceID: p.SequenceID,
-		}
-		c.server.ErrorLog.Printf("receive a smgp deliver response from %v[%d]\n",
-			c.Conn.RemoteAddr(), p.SequenceID)
-
-	case *pkg.SmgpDeliverRespPkt:
-		rsp = &Response{
-			Packet: &Packet{
-				Packer: p,
-				Conn:   c.Conn,
-			},
-		}
-		c.server.ErrorLog.Printf("receive a smgp deliver response from %v[%d]\n",
-			c.Conn.RemoteAddr(), p.SequenceID)
-
-	case *pkg.SmgpActiveTestReqPkt:
-		rsp = &Response{
-			Packet: &Packet{
-				Packer: p,
-				Conn:   c.Conn,
-			},
-			Packer: &pkg.SmgpActiveTestRespPkt{
-				SequenceID: p.SequenceID,
-			},
-			SequenceID: p.SequenceID,
-		}
-		c.server.ErrorLog.Printf("receive a smgp active request from %v[%d]\n",
-			c.Conn.RemoteAddr(), p.SequenceID)
-
-	case *pkg.SmgpActiveTestRespPkt:
-		rsp = &Response{
-			Packet: &Packet{
-				Packer: p,
-				Conn:   c.Conn,
-			},
-		}
-		c.server.ErrorLog.Printf("receive a smgp active response from %v[%d]\n",
-			c.Conn.RemoteAddr(), p.SequenceID)
-
-	case *pkg.SmgpExitReqPkt:
-		rsp = &Response{
-			Packet: &Packet{
-				Packer: p,
-				Conn:   c.Conn,
-			},
-			Packer: &pkg.SmgpExitRespPkt{
-				SequenceID: p.SequenceID,
-			},
-			SequenceID: p.SequenceID,
-		}
-		c.server.ErrorLog.Printf("receive a smgp exit request from %v[%d]\n",
-			c.Conn.RemoteAddr(), p.SequenceID)
-
-	case *pkg.SmgpExitRespPkt:
-		rsp = &Response{
-			Packet: &Packet{
-				Packer: p,
-				Conn:   c.Conn,
-			},
-		}
-		c.server.ErrorLog.Printf("receive a smgp exit response from %v[%d]\n",
-			c.Conn.RemoteAddr(), p.SequenceID)
-
-	case *pkg.SmgpQueryReqPkt:
-		rsp = &Response{
-			Packet: &Packet{
-				Packer: p,
-				Conn:   c.Conn,
-			},
-			Packer: &pkg.SmgpQueryRespPkt{
-				SequenceID: p.SequenceID,
-			},
-			SequenceID: p.SequenceID,
-		}
-		c.server.ErrorLog.Printf("receive a smgp query request from %v[%d]\n",
-			c.Conn.RemoteAddr(), p.SequenceID)
-
-	case *pkg.SmgpQueryRespPkt:
-		rsp = &Response{
-			Packet: &Packet{
-				Packer: p,
-				Conn:   c.Conn,
-			},
+
+	case *pkg.SmgpQueryUserStateReqPkt:
+		resp, ok := rsp.Packer.(*pkg.SmgpQueryUserStateRespPkt)
+		if !ok {
+			// 应答包未创建或被 RegisterPDU 替换为其它类型，以系统忙应答
+			c.server.ErrorLog.Printf("query user state: unexpected response %T\n", rsp.Packer)
+			resp = &pkg.SmgpQueryUserStateRespPkt{Status: pkg.STAT_SYS_BUSY}
+			rsp.Packer, rsp.SequenceID = resp, h.SequenceID
+			break
 		}
-		c.server.ErrorLog.Printf("receive a smgp query response from %v[%d]\n",
-			c.Conn.RemoteAddr(), p.SequenceID)
-	default:
-		return nil, pkg.NewOpError(ErrUnsupportedPkt,
-			fmt.Sprintf("readPacket: receive unsupported packet type: %#v", p))
+		c.fillUserState(resp, p)
 	}
+
+	c.server.ErrorLog.Printf("receive a %v from %v[%d]\n",
+		id, c.Conn.RemoteAddr(), h.SequenceID)
 	return rsp, nil
 }
 
@@ -321,6 +229,11 @@ func (c *conn) serve() {
 			if e, ok := err.(net.Error); ok && e.Timeout() {
 				continue
 			}
+			// 包体已读出，跳过未登记编解码的命令
+			if err == pkg.ErrRequestIDInvalid || err == pkg.ErrRequestIDNotSupported {
+				c.server.ErrorLog.Printf("skip a unsupported packet from %v\n", c.Conn.RemoteAddr())
+				continue
+			}
 			break
 		}
 
@@ -356,6 +269,16 @@ func (srv *Server) listenAndServe() error {
 	return srv.Serve(tcpKeepAliveListener{ln.(*net.TCPListener)})
 }
 
+func (srv *Server) ListenAndServe() error {
+	if srv.Handler == nil {
+		return ErrNoHandlers
+	}
+	if srv.ErrorLog == nil {
+		srv.ErrorLog = log.New(os.Stderr, "smgp server: ", log.LstdFlags)
+	}
+	return srv.listenAndServe()
+}
+
 func ListenAndServe(addr string, version uint8, t time.Duration, n int32, logWriter io.Writer, handlers ...Handler) error {
 	if addr == "" {
 		return ErrEmptyServerAddr
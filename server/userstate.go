@@ -0,0 +1,69 @@
+package server
+
+import (
+	"sync"
+
+	"github.com/boxtsecond/gosmgp/pkg"
+)
+
+// 用户状态查询接口，Server 收到 Query_UserState 时据此自动生成应答
+type UserStateProvider interface {
+	QueryUserState(number string) (state pkg.UserState, count uint32, err error)
+}
+
+// 基于内存的 UserStateProvider，未登记的号码视为不存在
+type MemUserStateProvider struct {
+	mu     sync.RWMutex
+	states map[string]memUserState
+}
+
+type memUserState struct {
+	state pkg.UserState
+	count uint32
+}
+
+func NewMemUserStateProvider() *MemUserStateProvider {
+	return &MemUserStateProvider{
+		states: make(map[string]memUserState),
+	}
+}
+
+func (m *MemUserStateProvider) SetUserState(number string, state pkg.UserState, count uint32) {
+	m.mu.Lock()
+	m.states[number] = memUserState{state: state, count: count}
+	m.mu.Unlock()
+}
+
+func (m *MemUserStateProvider) DeleteUserState(number string) {
+	m.mu.Lock()
+	delete(m.states, number)
+	m.mu.Unlock()
+}
+
+func (m *MemUserStateProvider) QueryUserState(number string) (pkg.UserState, uint32, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.states[number]
+	if !ok {
+		return pkg.USER_NOT_EXIST, 0, nil
+	}
+	return s.state, s.count, nil
+}
+
+// 按 UserStateProvider 填写 Query_UserState 应答。
+// 未设置 Provider 时以 11（命令字错）应答，表示不支持用户状态查询，
+// 不能默认应答 USER_NORMAL，否则对端会向停机或不存在的号码下发
+func (c *conn) fillUserState(resp *pkg.SmgpQueryUserStateRespPkt, p *pkg.SmgpQueryUserStateReqPkt) {
+	if c.server.UserState == nil {
+		resp.Status = pkg.STAT_COMMAND_ERR
+		return
+	}
+	state, count, err := c.server.UserState.QueryUserState(p.QueryUserAddr)
+	if err != nil {
+		c.server.ErrorLog.Printf("query user state of %s error: %v\n", p.QueryUserAddr, err)
+		resp.Status = pkg.STAT_SYS_BUSY
+		return
+	}
+	resp.UserStatus = state
+	resp.Count = count
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"testing"
+
+	"github.com/boxtsecond/gosmgp/pkg"
+)
+
+type failingUserState struct{}
+
+func (failingUserState) QueryUserState(string) (pkg.UserState, uint32, error) {
+	return 0, 0, errors.New("backend down")
+}
+
+func TestFillUserState(t *testing.T) {
+	mem := NewMemUserStateProvider()
+	mem.SetUserState("8618000000001", pkg.USER_SUSPENDED, 3)
+
+	tests := []struct {
+		name     string
+		provider UserStateProvider
+		number   string
+		status   pkg.Status
+		state    pkg.UserState
+		count    uint32
+	}{
+		{"no provider", nil, "8618000000001", pkg.STAT_COMMAND_ERR, pkg.USER_NORMAL, 0},
+		{"known number", mem, "8618000000001", pkg.STAT_OK, pkg.USER_SUSPENDED, 3},
+		{"unknown number", mem, "8618000000002", pkg.STAT_OK, pkg.USER_NOT_EXIST, 0},
+		{"provider error", failingUserState{}, "8618000000001", pkg.STAT_SYS_BUSY, pkg.USER_NORMAL, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &conn{server: &Server{UserState: tt.provider, ErrorLog: log.New(ioutil.Discard, "", 0)}}
+			resp := &pkg.SmgpQueryUserStateRespPkt{}
+			c.fillUserState(resp, &pkg.SmgpQueryUserStateReqPkt{QueryUserAddr: tt.number})
+			if resp.Status != tt.status || resp.UserStatus != tt.state || resp.Count != tt.count {
+				t.Errorf("got status %d state %v count %d, want %d %v %d",
+					resp.Status, resp.UserStatus, resp.Count, tt.status, tt.state, tt.count)
+			}
+		})
+	}
+}